@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// methods advertised by a response's Allow header, upper-cased in header order
+func allowedMethods(header http.Header) []string {
+	var methods []string
+	for _, value := range header.Values("Allow") {
+		for _, method := range strings.Split(value, ",") {
+			if method = strings.ToUpper(strings.TrimSpace(method)); method != "" {
+				methods = append(methods, method)
+			}
+		}
+	}
+	return methods
+}
+
+// why the Allow header breaks expect_allow, or "" if it lists every expected method
+func allowMismatch(endpoint Endpoint, allowed []string) string {
+	if len(allowed) == 0 {
+		return "no Allow header"
+	}
+	var missing []string
+	for _, method := range endpoint.ExpectAllow {
+		found := false
+		for _, allow := range allowed {
+			if strings.EqualFold(allow, method) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, strings.ToUpper(method))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Sprintf("Allow %s is missing %s", strings.Join(allowed, ", "), strings.Join(missing, ", "))
+	}
+	return ""
+}
+
+// expect_allow reads an HTTP response header, so not for websocket or dns endpoints
+func validateAllow(endpoint Endpoint) error {
+	if len(endpoint.ExpectAllow) > 0 && (endpoint.Type == typeWebSocket || endpoint.Type == typeDNS) {
+		return fmt.Errorf("%s: expect_allow is not supported for %s endpoints", endpoint.Name, endpoint.Type)
+	}
+	return nil
+}
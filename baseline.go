@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// -baseline: per-domain availability (percent) of a previous run, read from a
+// -summary-file style JSON array
+func readBaseline(path string) (map[string]float64, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && *updateBaseline {
+		return map[string]float64{}, nil // first run creates it
+	}
+	if err != nil {
+		return nil, err
+	}
+	var summaries []domainSummary
+	if err := json.Unmarshal(data, &summaries); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	baseline := make(map[string]float64, len(summaries))
+	for _, summary := range summaries {
+		if summary.Total > 0 {
+			baseline[summary.Domain] = summary.Availability
+		}
+	}
+	return baseline, nil
+}
+
+// Report each domain whose availability fell more than -baseline-tolerance
+// percentage points below the baseline; returns how many regressed.
+// Domains missing from either side are not compared.
+func compareBaseline(w io.Writer, stats map[string]*Stats, baseline map[string]float64) int {
+	regressed := 0
+	for _, summary := range domainSummaries(stats) {
+		previous, ok := baseline[summary.Domain]
+		if !ok || summary.Total == 0 {
+			continue
+		}
+		if summary.Availability < previous-*baselineTolerance {
+			fmt.Fprintf(w, "%s regressed: %.2f%% availability, baseline %.2f%% (tolerance %g)\n",
+				summary.Domain, summary.Availability, previous, *baselineTolerance)
+			regressed++
+		}
+	}
+	return regressed
+}
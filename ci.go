@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// -ci-summary: one endpoint's result over the run
+type ciEndpoint struct {
+	Name         string  `json:"name"`
+	Domain       string  `json:"domain"`
+	Total        int     `json:"total"`
+	Up           int     `json:"up"`
+	Availability float64 `json:"availability"` // percent
+	Pass         bool    `json:"pass"`         // availability at or above the threshold
+}
+
+// -ci-summary document: pass only if every endpoint passed
+type ciSummary struct {
+	Threshold    float64      `json:"threshold"`
+	Pass         bool         `json:"pass"`
+	Availability float64      `json:"availability"` // weighted overall, see weight
+	Endpoints    []ciEndpoint `json:"endpoints"`
+}
+
+// Write the -ci-summary JSON ("-" for stdout) for the enabled endpoints,
+// returning whether all of them met -ci-threshold. An endpoint with no
+// results fails.
+func writeCISummary(path string, endpoints []Endpoint) (bool, error) {
+	stats := snapshotEndpointStats()
+	summary := ciSummary{Threshold: *ciThreshold, Pass: true, Endpoints: []ciEndpoint{}}
+	summary.Availability, _ = weightedAvailability(stats)
+	for _, endpoint := range enabledEndpoints(endpoints) {
+		stat := stats[endpoint.Name]
+		result := ciEndpoint{Name: endpoint.Name, Domain: statsKey(endpoint), Total: stat.totalRequests,
+			Up: stat.upRequests}
+		if stat.totalRequests > 0 {
+			result.Availability = float64(stat.upRequests) / float64(stat.totalRequests) * 100
+			result.Pass = result.Availability >= *ciThreshold
+		}
+		summary.Pass = summary.Pass && result.Pass
+		summary.Endpoints = append(summary.Endpoints, result)
+	}
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return false, err
+	}
+	data = append(data, '\n')
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+	} else {
+		err = os.WriteFile(path, data, 0644)
+	}
+	return summary.Pass, err
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Per-endpoint overrides of the shared check client
+type ClientConfig struct {
+	Timeout            time.Duration `yaml:"timeout,omitempty"`              // instead of 2s
+	InsecureSkipVerify bool          `yaml:"insecure_skip_verify,omitempty"` // accept self-signed/invalid certificates
+	Proxy              string        `yaml:"proxy,omitempty"`                // http(s):// or socks5:// proxy url
+	ServerName         string        `yaml:"server_name,omitempty"`          // TLS SNI and verified name, e.g. behind a mesh sidecar
+}
+
+func (cfg *ClientConfig) validate(endpoint Endpoint) error {
+	if cfg == nil {
+		return nil
+	}
+	name := endpoint.Name
+	if cfg.Timeout < 0 {
+		return fmt.Errorf("%s: client timeout must not be negative", name)
+	}
+	if cfg.Proxy != "" {
+		u, err := url.Parse(cfg.Proxy)
+		if err != nil || u.Host == "" || u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" {
+			return fmt.Errorf("%s: client proxy %q must be an http://, https:// or socks5:// url", name, cfg.Proxy)
+		}
+	}
+	if cfg.ServerName != "" {
+		// SNI only exists on TLS connections
+		for _, target := range append([]string{endpoint.URL}, endpoint.URLs...) {
+			if target != "" && !strings.HasPrefix(target, "https://") && !strings.HasPrefix(target, "wss://") {
+				return fmt.Errorf("%s: client server_name needs an https:// url, got %s", name, target)
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	clientsMu sync.Mutex
+	clients   = make(map[ClientConfig]*http.Client) // built per distinct config, reused across cycles
+)
+
+// client for an endpoint: the shared one, or one built (once) for its client overrides
+func clientFor(endpoint Endpoint) (*http.Client, error) {
+	if endpoint.Client == nil {
+		return httpClient, nil
+	}
+	cfg := *endpoint.Client
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	if client, ok := clients[cfg]; ok {
+		return client, nil
+	}
+	transport, err := newTransport()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.InsecureSkipVerify || cfg.ServerName != "" {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify, ServerName: cfg.ServerName}
+	}
+	if cfg.Proxy != "" && mockAddr == "" {
+		proxy, _ := url.Parse(cfg.Proxy) // validated when the config was loaded
+		transport.Proxy = http.ProxyURL(proxy)
+		// the endpoint's proxy replaces -socks5; with -ssh-bastion the proxy is reached through the tunnel
+		if *socks5 != "" {
+			transport.DialContext = nil
+		}
+	}
+	client := &http.Client{Transport: transport, Timeout: httpClient.Timeout, CheckRedirect: httpClient.CheckRedirect}
+	if cfg.Timeout > 0 {
+		client.Timeout = cfg.Timeout
+	}
+	clients[cfg] = client
+	return client, nil
+}
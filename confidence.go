@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Wilson score interval for up successes out of total at -confidence, in
+// percent; unlike ±sqrt(p(1-p)/n) it stays within 0-100 and is honest for
+// small samples and availabilities near 100%
+func wilsonInterval(up, total int, confidence float64) (low, high float64) {
+	if total == 0 {
+		return 0, 100
+	}
+	z := math.Sqrt2 * math.Erfinv(confidence/100)
+	n := float64(total)
+	p := float64(up) / n
+	center := (p + z*z/(2*n)) / (1 + z*z/n)
+	margin := z / (1 + z*z/n) * math.Sqrt(p*(1-p)/n+z*z/(4*n*n))
+	return math.Max(0, center-margin) * 100, math.Min(1, center+margin) * 100
+}
+
+// e.g. " (95% CI 83-99%)" for the availability line, empty without -confidence
+func formatConfidence(stat *Stats) string {
+	if *confidence == 0 {
+		return ""
+	}
+	low, high := wilsonInterval(stat.upRequests, stat.totalRequests, *confidence)
+	return fmt.Sprintf(" (%g%% CI %d-%d%%)", *confidence, int(math.Floor(low)), int(math.Ceil(high)))
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cap on body bytes read for value extraction
+const maxBodyBytes = 1 << 20
+
+// Check depends_on names exist and are unambiguous, there are no cycles,
+// and every inject reference points at a key a dependency (or cached endpoint) extracts
+func validateDependencies(endpoints []Endpoint) error {
+	byName := make(map[string]*Endpoint, len(endpoints))
+	count := make(map[string]int, len(endpoints))
+	for i := range endpoints {
+		byName[endpoints[i].Name] = &endpoints[i]
+		count[endpoints[i].Name]++
+	}
+	for _, endpoint := range endpoints {
+		for _, name := range endpoint.DependsOn {
+			if _, exists := byName[name]; !exists {
+				return fmt.Errorf("%s: depends_on unknown endpoint %q", endpoint.Name, name)
+			}
+			if count[name] > 1 {
+				return fmt.Errorf("%s: depends_on %q matches %d endpoints", endpoint.Name, name, count[name])
+			}
+		}
+		for _, name := range templateDeps(endpoint) {
+			if _, exists := byName[name]; !exists {
+				return fmt.Errorf("%s: template refers to unknown endpoint %q", endpoint.Name, name)
+			}
+			if count[name] > 1 {
+				return fmt.Errorf("%s: template endpoint %q matches %d endpoints", endpoint.Name, name, count[name])
+			}
+		}
+		if err := validateTemplates(endpoint); err != nil {
+			return err
+		}
+		for _, name := range dependencies(endpoint) {
+			if dep, exists := byName[name]; exists && endpoint.enabled() && !dep.enabled() {
+				return fmt.Errorf("%s: depends on disabled endpoint %q", endpoint.Name, name)
+			}
+		}
+		for key, source := range endpoint.Extract {
+			kind, _, _ := strings.Cut(source, ":")
+			if kind != "header" && kind != "json" {
+				return fmt.Errorf("%s: extract %q must start with header: or json:", endpoint.Name, key)
+			}
+		}
+		for header, ref := range endpoint.Inject {
+			dep, key, _ := strings.Cut(ref, ".")
+			if _, exists := byName[dep]; !exists {
+				return fmt.Errorf("%s: inject %s refers to unknown endpoint %q", endpoint.Name, header, dep)
+			}
+			if !contains(endpoint.DependsOn, dep) && byName[dep].CacheTTL <= 0 {
+				return fmt.Errorf("%s: inject %s refers to %q which is not in depends_on and has no cache_ttl", endpoint.Name, header, dep)
+			}
+			if _, exists := byName[dep].Extract[key]; !exists {
+				return fmt.Errorf("%s: inject %s refers to %q which %q does not extract", endpoint.Name, header, key, dep)
+			}
+		}
+	}
+	// depth-first search for cycles: 1 = visiting, 2 = done
+	state := make(map[string]int, len(endpoints))
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("dependency cycle through %q", name)
+		case 2:
+			return nil
+		}
+		state[name] = 1
+		for _, dep := range dependencies(*byName[name]) {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[name] = 2
+		return nil
+	}
+	for _, endpoint := range endpoints {
+		if err := visit(endpoint.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// endpoints that must finish first: depends_on, cached endpoints referenced by
+// inject, and endpoints referenced by header/body templates
+func dependencies(endpoint Endpoint) []string {
+	names := append([]string(nil), endpoint.DependsOn...)
+	for _, ref := range endpoint.Inject {
+		dep, _, _ := strings.Cut(ref, ".")
+		if !contains(names, dep) {
+			names = append(names, dep)
+		}
+	}
+	for _, dep := range templateDeps(endpoint) {
+		if !contains(names, dep) {
+			names = append(names, dep)
+		}
+	}
+	return names
+}
+
+// extracted values kept across cycles for endpoints with cache_ttl
+type cachedExtract struct {
+	values  map[string]string
+	expires time.Time
+}
+
+var (
+	valueCacheMu sync.Mutex
+	valueCache   = make(map[string]cachedExtract) // by endpoint name
+)
+
+// fresh cached values for endpoint, if it uses cache_ttl
+func cachedValues(endpoint Endpoint) (map[string]string, bool) {
+	if endpoint.CacheTTL <= 0 {
+		return nil, false
+	}
+	valueCacheMu.Lock()
+	defer valueCacheMu.Unlock()
+	cached, exists := valueCache[endpoint.Name]
+	if !exists || time.Now().After(cached.expires) {
+		return nil, false
+	}
+	return cached.values, true
+}
+
+func cacheValues(endpoint Endpoint, values map[string]string) {
+	if endpoint.CacheTTL <= 0 {
+		return
+	}
+	valueCacheMu.Lock()
+	defer valueCacheMu.Unlock()
+	valueCache[endpoint.Name] = cachedExtract{values: values, expires: time.Now().Add(endpoint.CacheTTL)}
+}
+
+// whether any extract source reads from the response body
+func needsBody(extract map[string]string) bool {
+	for _, source := range extract {
+		if strings.HasPrefix(source, "json:") {
+			return true
+		}
+	}
+	return false
+}
+
+// Pull configured values out of a response's headers or JSON body
+func extractValues(extract map[string]string, header http.Header, body []byte) (map[string]string, error) {
+	if len(extract) == 0 {
+		return nil, nil
+	}
+	values := make(map[string]string, len(extract))
+	var doc interface{}
+	parsed := false
+	for key, source := range extract {
+		kind, path, _ := strings.Cut(source, ":")
+		switch kind {
+		case "header":
+			value := header.Get(path)
+			if value == "" {
+				return nil, fmt.Errorf("extract %s: response has no %s header", key, path)
+			}
+			values[key] = value
+		case "json":
+			if !parsed {
+				if err := json.Unmarshal(body, &doc); err != nil {
+					return nil, fmt.Errorf("extract %s: body is not JSON: %w", key, err)
+				}
+				parsed = true
+			}
+			value, err := lookupJSON(doc, path)
+			if err != nil {
+				return nil, fmt.Errorf("extract %s: %w", key, err)
+			}
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+// Walk a dot-separated path (object keys or array indexes) in decoded JSON
+func lookupJSON(doc interface{}, path string) (string, error) {
+	current := doc
+	for _, part := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			next, exists := node[part]
+			if !exists {
+				return "", fmt.Errorf("json field %q not found", path)
+			}
+			current = next
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node) {
+				return "", fmt.Errorf("json index %q out of range in %q", part, path)
+			}
+			current = node[i]
+		default:
+			return "", fmt.Errorf("json field %q not found", path)
+		}
+	}
+	switch value := current.(type) {
+	case string:
+		return value, nil
+	case nil:
+		return "", fmt.Errorf("json field %q is null", path)
+	default:
+		encoded, _ := json.Marshal(value)
+		return string(encoded), nil
+	}
+}
+
+func contains(list []string, target string) bool {
+	for _, item := range list {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
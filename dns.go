@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+const typeDNS = "dns"
+
+// dns endpoints: the record to resolve for url (a host name) and the answer expected
+type DNSCheck struct {
+	Record string `yaml:"record,omitempty"` // A (default), AAAA, CNAME or TXT
+	Expect string `yaml:"expect,omitempty"` // value that must be among the answers; empty accepts any
+	Server string `yaml:"server,omitempty"` // resolver host:port instead of the system one
+}
+
+var dnsRecords = []string{"A", "AAAA", "CNAME", "TXT"}
+
+// dns endpoints resolve url as a name: no request options apply
+func prepareDNS(endpoint *Endpoint) error {
+	if endpoint.DNS == nil {
+		endpoint.DNS = &DNSCheck{}
+	}
+	check := endpoint.DNS
+	check.Record = strings.ToUpper(check.Record)
+	if check.Record == "" {
+		check.Record = "A"
+	}
+	if !slices.Contains(dnsRecords, check.Record) {
+		return fmt.Errorf("%s: dns record must be one of %s, got %q", endpoint.Name, strings.Join(dnsRecords, ", "),
+			check.Record)
+	}
+	if endpoint.URL == "" || strings.Contains(endpoint.URL, "/") || len(endpoint.URLs) > 0 {
+		return fmt.Errorf("%s: dns endpoints need url set to a single host name, e.g. example.com", endpoint.Name)
+	}
+	if endpoint.Body != "" || len(endpoint.Headers) > 0 || endpoint.Method != "" {
+		return fmt.Errorf("%s: dns endpoints take no method, headers or body", endpoint.Name)
+	}
+	if check.Server != "" {
+		if _, _, err := net.SplitHostPort(check.Server); err != nil {
+			return fmt.Errorf("%s: dns server %q: want host:port", endpoint.Name, check.Server)
+		}
+	}
+	endpoint.Method = check.Record // shown by -list and -test-config
+	return nil
+}
+
+// Resolve the endpoint's record within timeout: UP when it resolves (to the
+// expected value, if set) under the latency threshold. Latency is the lookup time.
+func checkDNS(ctx context.Context, endpoint Endpoint, timeout time.Duration) checkResult {
+	check := endpoint.DNS
+	resolver := net.DefaultResolver
+	if check.Server != "" {
+		resolver = &net.Resolver{PreferGo: true, Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, check.Server)
+		}}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	answers, err := lookupRecord(ctx, resolver, check.Record, endpoint.URL)
+	latency := time.Since(start)
+	if err != nil {
+		return checkResult{reason: fmt.Sprintf("dns lookup failed: %v", err), category: errorCategory(err)}
+	}
+	result := checkResult{latency: latency, answers: answers}
+	assert := func(name, failure string) {
+		result.assertions = append(result.assertions, assertion{name: name, failure: failure})
+		if failure != "" && result.reason == "" {
+			result.reason, result.category = failure, name
+		}
+	}
+	var dnsFailure, latencyFailure string
+	if len(answers) == 0 {
+		dnsFailure = fmt.Sprintf("no %s records", check.Record)
+	} else if check.Expect != "" && !slices.ContainsFunc(answers, func(answer string) bool {
+		return strings.EqualFold(answer, strings.TrimSuffix(check.Expect, "."))
+	}) {
+		dnsFailure = fmt.Sprintf("%s %s is %s, expected %s", check.Record, endpoint.URL, strings.Join(answers, ", "),
+			check.Expect)
+	}
+	if latency >= latencyThreshold {
+		latencyFailure = fmt.Sprintf("latency %s exceeds %s", latency.Round(time.Millisecond), latencyThreshold)
+	}
+	assert(typeDNS, dnsFailure)
+	assert(failLatency, latencyFailure)
+	result.up = result.reason == ""
+	return result
+}
+
+// answers as strings, names without their trailing dot
+func lookupRecord(ctx context.Context, resolver *net.Resolver, record, name string) ([]string, error) {
+	switch record {
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		return []string{strings.TrimSuffix(cname, ".")}, nil
+	case "TXT":
+		return resolver.LookupTXT(ctx, name)
+	}
+	network := "ip4"
+	if record == "AAAA" {
+		network = "ip6"
+	}
+	ips, err := resolver.LookupIP(ctx, network, name)
+	if err != nil {
+		return nil, err
+	}
+	answers := make([]string, len(ips))
+	for i, ip := range ips {
+		answers[i] = ip.String()
+	}
+	return answers, nil
+}
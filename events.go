@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// Last -events-buffer check results, oldest overwritten first, for /events
+type eventRing struct {
+	mu     sync.Mutex
+	events []resultEvent
+	next   int // slot the next event goes in once the ring is full
+}
+
+var recentEvents eventRing
+
+func (ring *eventRing) add(event resultEvent) {
+	if *eventsBuffer <= 0 {
+		return
+	}
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	if len(ring.events) < *eventsBuffer {
+		ring.events = append(ring.events, event)
+		return
+	}
+	ring.events[ring.next] = event
+	ring.next = (ring.next + 1) % len(ring.events)
+}
+
+// buffered events, oldest first
+func (ring *eventRing) list() []resultEvent {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	events := make([]resultEvent, 0, len(ring.events))
+	events = append(events, ring.events[ring.next:]...)
+	return append(events, ring.events[:ring.next]...)
+}
+
+// /events: recent results as a JSON array, oldest first; ?limit=N keeps the newest N
+// and ?endpoint=<name> only that endpoint's
+func serveEvents(w http.ResponseWriter, r *http.Request) {
+	events := recentEvents.list()
+	if name := r.URL.Query().Get("endpoint"); name != "" {
+		matching := events[:0]
+		for _, event := range events {
+			if event.Name == name {
+				matching = append(matching, event)
+			}
+		}
+		events = matching
+	}
+	if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit >= 0 && limit < len(events) {
+		events = events[len(events)-limit:]
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// Failure categories, counted per domain. Checks that fail an assertion use
+// the assertion's name (status, latency, graphql, redirects, extract).
+const (
+	failTimeout    = "timeout"    // request, body read or cycle deadline timed out
+	failConnection = "connection" // DNS, connect, TLS or protocol error
+	failDependency = "dependency" // a depends_on endpoint was DOWN
+	failConfig     = "config"     // the request could not be built
+	failStatus     = "status"
+	failLatency    = "latency"
+	failPanic      = "panic" // the check panicked
+	failChaos      = "chaos" // simulated by -chaos, nothing was sent
+	failOther      = "other" // DOWN without one of the above, e.g. via success_policy
+)
+
+// Request errors are timeouts whichever mechanism fired - the client Timeout,
+// a context deadline or a dial/TLS timeout - and connection errors otherwise
+func errorCategory(err error) string {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		return failTimeout
+	}
+	return failConnection
+}
+
+// count a DOWN result's category (caller holds statsMu)
+func (stat *Stats) recordFailure(result checkResult) {
+	if result.up {
+		return
+	}
+	category := result.category
+	if category == "" {
+		category = failOther
+	}
+	if stat.failures == nil {
+		stat.failures = make(map[string]int)
+	}
+	stat.failures[category]++
+}
+
+// e.g. "status 2, timeout 1", most frequent first
+func formatFailures(failures map[string]int) string {
+	categories := make([]string, 0, len(failures))
+	for category := range failures {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if failures[categories[i]] != failures[categories[j]] {
+			return failures[categories[i]] > failures[categories[j]]
+		}
+		return categories[i] < categories[j]
+	})
+	parts := make([]string, len(categories))
+	for i, category := range categories {
+		parts[i] = fmt.Sprintf("%s %d", category, failures[category])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -1,5 +1,12 @@
 module health-check
 
-go 1.21.6
+go 1.26.0
 
-require gopkg.in/yaml.v3 v3.0.1 // indirect
+require (
+	golang.org/x/crypto v0.57.0
+	golang.org/x/net v0.59.0
+	google.golang.org/protobuf v1.36.12
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.48.0 // indirect
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// lines of difference shown for a golden_file mismatch
+const goldenDiffLines = 5
+
+// Read the endpoint's golden_file now (again on each config refresh); it has to
+// fit within the body read limit to ever match
+func loadGolden(endpoint *Endpoint) error {
+	if endpoint.GoldenFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(endpoint.GoldenFile)
+	if err != nil {
+		return fmt.Errorf("%s: golden_file: %w", endpoint.Name, err)
+	}
+	if len(data) >= maxBodyBytes {
+		return fmt.Errorf("%s: golden_file %s is over the %d byte body limit", endpoint.Name, endpoint.GoldenFile,
+			maxBodyBytes)
+	}
+	if endpoint.ReadBytes > 0 {
+		return fmt.Errorf("%s: golden_file needs the whole body, remove read_bytes", endpoint.Name)
+	}
+	endpoint.golden = data
+	return nil
+}
+
+// runs of whitespace collapse to one space, ends trimmed (golden_ignore_whitespace)
+func normalizeWhitespace(b []byte) []byte {
+	return []byte(strings.Join(strings.Fields(string(b)), " "))
+}
+
+// "" when body matches the golden file, else the reason plus the first
+// differing lines ("-" golden, "+" response)
+func goldenMismatch(endpoint Endpoint, body []byte) (string, []string) {
+	want, got := endpoint.golden, body
+	if endpoint.GoldenIgnoreWhitespace {
+		want, got = normalizeWhitespace(want), normalizeWhitespace(got)
+	}
+	if bytes.Equal(want, got) {
+		return "", nil
+	}
+	if len(body) >= maxBodyBytes {
+		return fmt.Sprintf("body exceeds the %d byte limit for golden_file", maxBodyBytes), nil
+	}
+	// first differing byte, reported by line
+	i := 0
+	for i < len(want) && i < len(got) && want[i] == got[i] {
+		i++
+	}
+	line := bytes.Count(got[:i], []byte("\n")) + 1
+	wantLines, gotLines := strings.Split(string(want), "\n"), strings.Split(string(got), "\n")
+	var diff []string
+	for n := line - 1; n < max(len(wantLines), len(gotLines)) && len(diff) < goldenDiffLines; n++ {
+		w, g := lineAt(wantLines, n), lineAt(gotLines, n)
+		if w == g {
+			continue
+		}
+		if n < len(wantLines) {
+			diff = append(diff, fmt.Sprintf("%d - %s", n+1, w))
+		}
+		if n < len(gotLines) {
+			diff = append(diff, fmt.Sprintf("%d + %s", n+1, g))
+		}
+	}
+	return fmt.Sprintf("body differs from %s at line %d", endpoint.GoldenFile, line), diff
+}
+
+func lineAt(lines []string, n int) string {
+	if n < len(lines) {
+		return lines[n]
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const typeGraphQL = "graphql"
+
+// Turn a graphql endpoint's query into a POSTed JSON request
+func prepareGraphQL(endpoint *Endpoint) error {
+	payload, err := json.Marshal(map[string]string{"query": endpoint.Body})
+	if err != nil {
+		return err
+	}
+	endpoint.Body = string(payload)
+	if endpoint.Method == "" {
+		endpoint.Method = http.MethodPost
+	}
+	if endpoint.Headers == nil {
+		endpoint.Headers = make(map[string]string)
+	}
+	if !hasHeader(endpoint.Headers, "Content-Type") { // any case, e.g. content-type
+		endpoint.Headers["Content-Type"] = "application/json"
+	}
+	return nil
+}
+
+// GraphQL reports failures with HTTP 200 and a top-level errors array:
+// describe the first error, or "" if there are none
+func graphqlErrors(body []byte) string {
+	var response struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Sprintf("graphql response is not JSON: %v", err)
+	}
+	if len(response.Errors) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("graphql errors (%d): %s", len(response.Errors), response.Errors[0].Message)
+}
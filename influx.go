@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// where -influx sends each cycle's line protocol batch
+var influxSink func([]byte) error
+
+// -influx destination: "-" for stdout, an http(s) write URL (POSTed), or a file to append to
+func newInfluxSink(dest string) (func([]byte) error, error) {
+	switch {
+	case dest == "-":
+		return func(batch []byte) error {
+			_, err := os.Stdout.Write(batch)
+			return err
+		}, nil
+	case strings.HasPrefix(dest, "http://") || strings.HasPrefix(dest, "https://"):
+		return func(batch []byte) error {
+			resp, err := configClient.Post(dest, "text/plain; charset=utf-8", bytes.NewReader(batch))
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			io.Copy(io.Discard, resp.Body)
+			if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+				return fmt.Errorf("influx write %s: %s", dest, resp.Status)
+			}
+			return nil
+		}, nil
+	default:
+		file, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		return func(batch []byte) error {
+			_, err := file.Write(batch)
+			return err
+		}, nil
+	}
+}
+
+// escape commas, spaces and equals signs in tag values
+var influxTagEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+
+// Line protocol for the current stats: one health_check point per domain and
+// one health_check_endpoint point per endpoint, all stamped now
+func influxLines(stats map[string]*Stats) []byte {
+	stats = snapshotStats(stats)
+	now := time.Now().UnixNano()
+	regionTag := ""
+	if *region != "" {
+		regionTag = ",region=" + influxTagEscaper.Replace(*region)
+	}
+	var b bytes.Buffer
+	domains := make([]string, 0, len(stats))
+	for domain := range stats {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	for _, domain := range domains {
+		stat := stats[domain]
+		if stat.totalRequests == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "health_check,domain=%s%s availability=%g,total=%di,up=%di",
+			influxTagEscaper.Replace(domain), regionTag,
+			float64(stat.upRequests)/float64(stat.totalRequests)*100, stat.totalRequests, stat.upRequests)
+		if stat.latency.count > 0 {
+			fmt.Fprintf(&b, ",latency_ms=%g", stat.latency.sum/float64(stat.latency.count)*1000)
+		}
+		fmt.Fprintf(&b, " %d\n", now)
+	}
+	endpoints := snapshotEndpointStats()
+	names := make([]string, 0, len(endpoints))
+	for name := range endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		stat := endpoints[name]
+		fmt.Fprintf(&b, "health_check_endpoint,name=%s,domain=%s%s availability=%g,total=%di,up=%di %d\n",
+			influxTagEscaper.Replace(name), influxTagEscaper.Replace(stat.domain), regionTag,
+			float64(stat.upRequests)/float64(stat.totalRequests)*100, stat.totalRequests, stat.upRequests, now)
+	}
+	return b.Bytes()
+}
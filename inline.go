@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// repeatable string flag
+type stringList []string
+
+func (list *stringList) String() string {
+	return strings.Join(*list, ",")
+}
+
+func (list *stringList) Set(value string) error {
+	*list = append(*list, value)
+	return nil
+}
+
+// endpoints given on the command line instead of a config file
+var inlineURLs, inlineHeaders stringList
+
+func init() {
+	flag.Var(&inlineURLs, "url", "check this url without a config file (repeatable)")
+	flag.Var(&inlineHeaders, "header", `"Name: value" header sent to every -url endpoint (repeatable)`)
+}
+
+// One endpoint per -url, named after its url, sharing -method and -header
+func inlineEndpoints() ([]Endpoint, error) {
+	headers := make(map[string]string)
+	for _, header := range inlineHeaders {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("-header %q: want \"Name: value\"", header)
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	endpoints := make([]Endpoint, 0, len(inlineURLs))
+	for _, target := range inlineURLs {
+		u, err := url.Parse(target)
+		if err != nil {
+			return nil, fmt.Errorf("-url %q: %w", target, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("-url %q: must be http:// or https://", target)
+		}
+		endpoints = append(endpoints, Endpoint{
+			Name:    target,
+			URL:     target,
+			Method:  strings.ToUpper(*inlineMethod),
+			Headers: headers,
+		})
+	}
+	return endpoints, nil
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+)
+
+// per-endpoint counters, for -group-by-label and labelled metrics
+type endpointStat struct {
+	labels        map[string]string
+	description   string
+	weight        float64 // in the overall availability
+	domain        string  // stats bucket the endpoint counts toward
+	totalRequests int
+	upRequests    int
+	up            bool // latest result
+}
+
+// counters by endpoint name, guarded by statsMu
+var endpointStats = make(map[string]*endpointStat)
+
+// label keys must be valid Prometheus label names and not clash with built-in labels
+var labelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+func validateLabels(endpoint Endpoint) error {
+	for key := range endpoint.Labels {
+		if !labelName.MatchString(key) || key == "domain" || key == "endpoint" || key == "le" || key == "region" {
+			return fmt.Errorf("%s: invalid label name %q", endpoint.Name, key)
+		}
+	}
+	return nil
+}
+
+// count a result against its endpoint; caller holds statsMu
+func recordEndpoint(endpoint Endpoint, up bool) {
+	stat, exists := endpointStats[endpoint.Name]
+	if !exists {
+		stat = &endpointStat{}
+		endpointStats[endpoint.Name] = stat
+	}
+	stat.labels = endpoint.Labels
+	stat.description = endpoint.Description
+	stat.weight = endpoint.weight()
+	stat.domain = statsKey(endpoint)
+	stat.totalRequests++
+	if up {
+		stat.upRequests++
+	}
+	stat.up = up
+}
+
+// "name: description" for each described endpoint counting toward domain, sorted by name
+func endpointDescriptions(endpoints map[string]endpointStat, domain string) []string {
+	var lines []string
+	for name, stat := range endpoints {
+		if stat.domain == domain && stat.description != "" {
+			lines = append(lines, name+": "+stat.description)
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// copy of endpointStats, safe to read without holding statsMu
+func snapshotEndpointStats() map[string]endpointStat {
+	statsMu.RLock()
+	defer statsMu.RUnlock()
+	snapshot := make(map[string]endpointStat, len(endpointStats))
+	for name, stat := range endpointStats {
+		snapshot[name] = *stat
+	}
+	return snapshot
+}
+
+// Log availability aggregated over all endpoints sharing a value of label
+func printGroupedAvailability(w io.Writer, label string) {
+	groups := make(map[string]*endpointStat)
+	for _, stat := range snapshotEndpointStats() {
+		value, exists := stat.labels[label]
+		if !exists {
+			value = "(none)"
+		}
+		group, exists := groups[value]
+		if !exists {
+			group = &endpointStat{}
+			groups[value] = group
+		}
+		group.totalRequests += stat.totalRequests
+		group.upRequests += stat.upRequests
+	}
+	values := make([]string, 0, len(groups))
+	for value := range groups {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	for _, value := range values {
+		group := groups[value]
+		if group.totalRequests < *minSamples {
+			fmt.Fprintf(w, "%s=%s collecting data (%d/%d samples)\n", label, value, group.totalRequests, *minSamples)
+			continue
+		}
+		availability := roundAvailability(float64(group.upRequests) / float64(group.totalRequests) * 100)
+		fmt.Fprintf(w, "%s=%s has %d%% availability percentage\n", label, value, availability)
+	}
+}
+
+// -compact: one line per cycle for status bars, e.g. "UP 48/50 | worst payments 82%":
+// endpoints whose latest check was UP out of all checked, then the endpoint with the
+// lowest availability (ties by name). The worst part is left out until there are results.
+func printCompact(w io.Writer) {
+	endpoints := snapshotEndpointStats()
+	names := make([]string, 0, len(endpoints))
+	for name := range endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	up, worst, worstAvailability := 0, "", 101.0
+	for _, name := range names {
+		stat := endpoints[name]
+		if stat.up {
+			up++
+		}
+		if stat.totalRequests == 0 {
+			continue
+		}
+		if availability := float64(stat.upRequests) / float64(stat.totalRequests) * 100; availability < worstAvailability {
+			worst, worstAvailability = name, availability
+		}
+	}
+	line := fmt.Sprintf("UP %d/%d", up, len(names))
+	if worst != "" {
+		line += fmt.Sprintf(" | worst %s %d%%", worst, roundAvailability(worstAvailability))
+	}
+	fmt.Fprintln(w, line)
+}
+
+// Percent availability across endpoints: each endpoint's own availability
+// weighted by its weight. ok is false until a weighted endpoint has results.
+func weightedAvailability(endpoints map[string]endpointStat) (availability float64, ok bool) {
+	var sum, weights float64
+	for _, stat := range endpoints {
+		if stat.totalRequests == 0 || stat.weight == 0 {
+			continue
+		}
+		sum += stat.weight * float64(stat.upRequests) / float64(stat.totalRequests)
+		weights += stat.weight
+	}
+	if weights == 0 {
+		return 0, false
+	}
+	return sum / weights * 100, true
+}
+
+// whether any endpoint sets a weight other than the default 1
+func weighted(endpoints map[string]endpointStat) bool {
+	for _, stat := range endpoints {
+		if stat.weight != 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Write per-endpoint counters with endpoint labels as metric label dimensions
+func writeEndpointMetrics(w io.Writer, counterFamily func(string) string) {
+	stats := snapshotEndpointStats()
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	series := func(name string) string {
+		labels := fmt.Sprintf("endpoint=%q", name) + regionLabel()
+		keys := make([]string, 0, len(stats[name].labels))
+		for key := range stats[name].labels {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			labels += fmt.Sprintf(",%s=%q", key, stats[name].labels[key])
+		}
+		return labels
+	}
+	fmt.Fprintf(w, "# HELP %s Health checks run per endpoint.\n", counterFamily("health_check_endpoint_requests_total"))
+	fmt.Fprintf(w, "# TYPE %s counter\n", counterFamily("health_check_endpoint_requests_total"))
+	for _, name := range names {
+		fmt.Fprintf(w, "health_check_endpoint_requests_total{%s} %d\n", series(name), stats[name].totalRequests)
+	}
+	fmt.Fprintf(w, "# HELP %s Health checks counted UP per endpoint.\n", counterFamily("health_check_endpoint_up_total"))
+	fmt.Fprintf(w, "# TYPE %s counter\n", counterFamily("health_check_endpoint_up_total"))
+	for _, name := range names {
+		fmt.Fprintf(w, "health_check_endpoint_up_total{%s} %d\n", series(name), stats[name].upRequests)
+	}
+	if availability, ok := weightedAvailability(stats); ok {
+		labels := ""
+		if *region != "" {
+			labels = fmt.Sprintf("{region=%q}", *region)
+		}
+		fmt.Fprintln(w, "# HELP health_check_overall_availability Percent availability across endpoints, weighted by endpoint weight.")
+		fmt.Fprintln(w, "# TYPE health_check_overall_availability gauge")
+		fmt.Fprintf(w, "health_check_overall_availability%s %g\n", labels, availability)
+	}
+}
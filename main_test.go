@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// run with -race: checks write stats while the summary, /metrics and /stats read them
+func TestStatsConcurrentAccess(t *testing.T) {
+	endpoints := []Endpoint{
+		{Name: "a", URL: "http://a.example.com/health"},
+		{Name: "b", URL: "http://b.example.com/health"},
+	}
+	stats := make(map[string]*Stats)
+	for _, endpoint := range endpoints {
+		stats[statsKey(endpoint)] = &Stats{}
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				result := checkResult{up: j%3 != 0, status: 200, latency: time.Duration(j+1) * time.Millisecond}
+				if !result.up {
+					result.status, result.reason, result.category = 503, "status 503", failStatus
+				}
+				updateStats(stats, endpoints[(i+j)%len(endpoints)], result)
+			}
+		}(i)
+	}
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				writeMetrics(io.Discard, stats, j%2 == 0)
+				domainSummaries(stats)
+				printAvailability(io.Discard, stats)
+			}
+		}()
+	}
+	wg.Wait()
+	total := 0
+	for _, stat := range snapshotStats(stats) {
+		total += stat.totalRequests
+	}
+	if total != 4*200 {
+		t.Errorf("counted %d results, want %d", total, 4*200)
+	}
+}
+
+// a panicking check is DOWN with category panic, and the rest of the cycle still runs
+func TestCheckPanicRecovered(t *testing.T) {
+	defer func(check func(context.Context, Endpoint, map[string]map[string]string) checkResult) {
+		checkFunc = check
+	}(checkFunc)
+	checkFunc = func(ctx context.Context, endpoint Endpoint, deps map[string]map[string]string) checkResult {
+		if endpoint.Name == "b" {
+			var values map[string]string
+			values["boom"] = "assignment to nil map"
+		}
+		return checkResult{up: true, status: 200, latency: time.Millisecond}
+	}
+	endpoints := []Endpoint{
+		{Name: "a", URL: "http://a.example.com/health"},
+		{Name: "b", URL: "http://b.example.com/health"},
+		{Name: "c", URL: "http://c.example.com/health"},
+	}
+	stats := make(map[string]*Stats)
+	for _, endpoint := range endpoints {
+		stats[statsKey(endpoint)] = &Stats{}
+	}
+	runCheck(context.Background(), endpoints, stats, 0)
+	for _, endpoint := range endpoints {
+		stat := stats[statsKey(endpoint)]
+		if stat.totalRequests != 1 {
+			t.Fatalf("%s: %d results, want 1", endpoint.Name, stat.totalRequests)
+		}
+		if endpoint.Name != "b" {
+			if stat.upRequests != 1 {
+				t.Errorf("%s: DOWN (%s), want UP", endpoint.Name, stat.lastError)
+			}
+			continue
+		}
+		if stat.upRequests != 0 {
+			t.Errorf("b: UP, want DOWN")
+		}
+		if stat.failures[failPanic] != 1 {
+			t.Errorf("b: failures %v, want one %s", stat.failures, failPanic)
+		}
+		if !strings.HasPrefix(stat.lastError, "panic: ") {
+			t.Errorf("b: reason %q, want a panic reason", stat.lastError)
+		}
+	}
+}
+
+// -output appends across opens and keeps one previous generation once -output-max-bytes is reached
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.log")
+	write := func(rf *rotatingFile, line string) {
+		t.Helper()
+		if _, err := io.WriteString(rf, line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(path string) string {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	rf, err := openRotatingFile(path, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write(rf, "first 1234\n")
+	rf.Close()
+	if rf, err = openRotatingFile(path, 20); err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+	write(rf, "second\n")
+	if got, want := read(path), "first 1234\nsecond\n"; got != want {
+		t.Fatalf("after reopening: %q, want %q", got, want)
+	}
+	write(rf, "third 1234\n") // 29 bytes would pass 20: rotate first
+	if got, want := read(path+".1"), "first 1234\nsecond\n"; got != want {
+		t.Errorf("previous generation: %q, want %q", got, want)
+	}
+	if got, want := read(path), "third 1234\n"; got != want {
+		t.Errorf("after rotating: %q, want %q", got, want)
+	}
+
+	// a rotation that can't rename keeps appending to the current file
+	os.Remove(path + ".1")
+	if err := os.MkdirAll(filepath.Join(path+".1", "blocked"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write(rf, "fourth 1234\n")
+	write(rf, "fifth\n")
+	if got, want := read(path), "third 1234\nfourth 1234\nfifth\n"; got != want {
+		t.Errorf("after a failed rotation: %q, want %q", got, want)
+	}
+}
+
+// a dependency missing from the cycle makes its dependent DOWN instead of waiting on another endpoint
+func TestMissingDependency(t *testing.T) {
+	defer func(check func(context.Context, Endpoint, map[string]map[string]string) checkResult) {
+		checkFunc = check
+	}(checkFunc)
+	checkFunc = func(ctx context.Context, endpoint Endpoint, deps map[string]map[string]string) checkResult {
+		return checkResult{up: true, status: 200, latency: time.Millisecond}
+	}
+	endpoints := []Endpoint{{Name: "orders", URL: "http://orders.example.com/health", DependsOn: []string{"login"}}}
+	stats := map[string]*Stats{statsKey(endpoints[0]): {}}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	runCheck(ctx, endpoints, stats, 0)
+	if ctx.Err() != nil {
+		t.Fatal("runCheck waited for the cycle deadline")
+	}
+	stat := stats[statsKey(endpoints[0])]
+	if stat.upRequests != 0 || stat.failures[failDependency] != 1 {
+		t.Errorf("up %d, failures %v: want DOWN with category %s", stat.upRequests, stat.failures, failDependency)
+	}
+	if want := `dependency "login" not configured`; stat.lastError != want {
+		t.Errorf("reason %q, want %q", stat.lastError, want)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scheduled window during which results are not counted: weekly ("Sat 02:00-04:00"),
+// daily ("daily 23:30-00:30", may wrap past midnight, checker's local time) or a fixed
+// RFC 3339 range ("2026-10-20T01:00:00Z/2026-10-20T03:00:00Z")
+type maintenanceWindow struct {
+	start, end time.Time     // fixed range
+	weekday    time.Weekday  // recurring, when !everyDay
+	everyDay   bool          // recurring daily
+	from, to   time.Duration // recurring: offsets into the day
+}
+
+func parseMaintenanceWindow(spec string) (maintenanceWindow, error) {
+	spec = strings.TrimSpace(spec)
+	invalid := fmt.Errorf("invalid maintenance window %q (want e.g. \"Sat 02:00-04:00\", \"daily 23:30-00:30\" or \"<RFC 3339>/<RFC 3339>\")", spec)
+	if from, to, ok := strings.Cut(spec, "/"); ok {
+		start, err1 := time.Parse(time.RFC3339, from)
+		end, err2 := time.Parse(time.RFC3339, to)
+		if err1 != nil || err2 != nil || !end.After(start) {
+			return maintenanceWindow{}, invalid
+		}
+		return maintenanceWindow{start: start, end: end}, nil
+	}
+	day, hours, ok := strings.Cut(spec, " ")
+	if !ok {
+		return maintenanceWindow{}, invalid
+	}
+	var w maintenanceWindow
+	if strings.EqualFold(day, "daily") {
+		w.everyDay = true
+	} else if w.weekday, ok = parseWeekday(day); !ok {
+		return maintenanceWindow{}, invalid
+	}
+	from, to, ok := strings.Cut(strings.TrimSpace(hours), "-")
+	var err1, err2 error
+	if w.from, err1 = parseClock(from); err1 != nil || !ok {
+		return maintenanceWindow{}, invalid
+	}
+	if w.to, err2 = parseClock(to); err2 != nil || w.from == w.to {
+		return maintenanceWindow{}, invalid
+	}
+	return w, nil
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if len(name) >= 3 && strings.HasPrefix(strings.ToLower(day.String()), strings.ToLower(name)) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// "HH:MM" as an offset into the day
+func parseClock(clock string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func (w maintenanceWindow) contains(t time.Time) bool {
+	if !w.start.IsZero() {
+		return !t.Before(w.start) && t.Before(w.end)
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := t.Sub(midnight)
+	on := func(day time.Weekday) bool { return w.everyDay || day == w.weekday }
+	if w.from < w.to {
+		return on(t.Weekday()) && offset >= w.from && offset < w.to
+	}
+	// wraps past midnight: the evening part on the day itself, the morning part the day after
+	return on(t.Weekday()) && offset >= w.from || on((t.Weekday()+6)%7) && offset < w.to
+}
+
+func (w *maintenanceWindow) UnmarshalYAML(node *yaml.Node) error {
+	parsed, err := parseMaintenanceWindow(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	*w = parsed
+	return nil
+}
+
+// -maintenance windows applying to every endpoint
+var globalMaintenance []maintenanceWindow
+
+func parseMaintenanceWindows(list string) ([]maintenanceWindow, error) {
+	var windows []maintenanceWindow
+	for _, spec := range strings.Split(list, ",") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		w, err := parseMaintenanceWindow(spec)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// endpoint is in one of its own or the global maintenance windows at t
+func inMaintenance(endpoint Endpoint, t time.Time) bool {
+	for _, w := range append(endpoint.Maintenance, globalMaintenance...) {
+		if w.contains(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// other checker instances' /stats urls, pulled each cycle and merged into this one's view
+var mergeFrom stringList
+
+func init() {
+	flag.Var(&mergeFrom, "merge-from", "pull another instance's /stats url each cycle and report stats merged with it (repeatable)")
+}
+
+// largest /stats body accepted from a peer
+const maxPeerStatsBytes = 10 << 20
+
+// Latest /stats snapshot from each peer instance, by -merge-from url or the instance
+// a push named. Peers' counts are already cumulative, so a new snapshot replaces the
+// last one instead of adding to it.
+var (
+	peersMu sync.Mutex
+	peers   = make(map[string][]domainSummary)
+)
+
+func storePeer(instance string, summaries []domainSummary) {
+	peersMu.Lock()
+	peers[instance] = summaries
+	peersMu.Unlock()
+}
+
+func peerSnapshots() [][]domainSummary {
+	peersMu.Lock()
+	defer peersMu.Unlock()
+	snapshots := make([][]domainSummary, 0, len(peers))
+	for _, summaries := range peers {
+		snapshots = append(snapshots, summaries)
+	}
+	return snapshots
+}
+
+// Fetch every -merge-from url concurrently; a peer that can't be reached keeps its last snapshot
+func pullPeers() {
+	var wg sync.WaitGroup
+	for _, target := range mergeFrom {
+		wg.Add(1)
+		go func(target string) {
+			defer wg.Done()
+			summaries, err := fetchPeer(target)
+			if err != nil {
+				log.Printf("Error pulling stats from %s: %v", target, err)
+				return
+			}
+			storePeer(target, summaries)
+		}(target)
+	}
+	wg.Wait()
+}
+
+func fetchPeer(target string) ([]domainSummary, error) {
+	resp, err := configClient.Get(target)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %s", resp.Status)
+	}
+	return decodePeer(resp.Body)
+}
+
+func decodePeer(r io.Reader) ([]domainSummary, error) {
+	var summaries []domainSummary
+	if err := json.NewDecoder(io.LimitReader(r, maxPeerStatsBytes)).Decode(&summaries); err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+// POST /stats?instance=<name>: a peer pushing its /stats array, stored like a pulled one
+func servePeerPush(w http.ResponseWriter, r *http.Request) {
+	instance := r.URL.Query().Get("instance")
+	if instance == "" {
+		http.Error(w, "instance query parameter required", http.StatusBadRequest)
+		return
+	}
+	summaries, err := decodePeer(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("decoding stats: %v", err), http.StatusBadRequest)
+		return
+	}
+	storePeer(instance, summaries)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// -push-stats-to: POST this instance's /stats array to a leader each cycle, named
+// by -region or else the host name
+func pushStats(stats map[string]*Stats) {
+	if *pushStatsTo == "" {
+		return
+	}
+	instance := *region
+	if instance == "" {
+		instance, _ = os.Hostname()
+	}
+	body, err := json.Marshal(domainSummaries(stats))
+	if err != nil {
+		return
+	}
+	target, err := url.Parse(*pushStatsTo)
+	if err != nil {
+		log.Printf("Error pushing stats to %s: %v", *pushStatsTo, err)
+		return
+	}
+	query := target.Query()
+	query.Set("instance", instance)
+	target.RawQuery = query.Encode()
+	resp, err := configClient.Post(target.String(), "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("Error pushing stats to %s: %v", *pushStatsTo, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		log.Printf("Error pushing stats to %s: %s", *pushStatsTo, resp.Status)
+	}
+}
+
+// whether any peer stats have been pulled or pushed
+func havePeers() bool {
+	peersMu.Lock()
+	defer peersMu.Unlock()
+	return len(peers) > 0
+}
+
+// This instance's stats merged with the latest snapshot from each peer, per domain:
+// total, up, degraded, failure and status counts add up and availability is recomputed
+// from the sums; latency and phase averages are weighted by each side's latency_count;
+// uptime is the shortest (0 if DOWN anywhere) and the certificate the earliest expiring.
+func mergedSummaries(stats map[string]*Stats) []domainSummary {
+	return mergeSummaries(append([][]domainSummary{domainSummaries(stats)}, peerSnapshots()...))
+}
+
+func mergeSummaries(sets [][]domainSummary) []domainSummary {
+	merged := make(map[string]*domainSummary)
+	for _, set := range sets {
+		for _, s := range set {
+			m, exists := merged[s.Domain]
+			if !exists {
+				m = &domainSummary{Domain: s.Domain, UptimeSeconds: s.UptimeSeconds,
+					Failures: make(map[string]int), Statuses: make(map[int]int)}
+				merged[s.Domain] = m
+			}
+			m.Instances += max(s.Instances, 1)
+			if n := m.LatencyCount + s.LatencyCount; n > 0 {
+				weigh := func(a, b float64) float64 {
+					return (a*float64(m.LatencyCount) + b*float64(s.LatencyCount)) / float64(n)
+				}
+				m.AvgLatencyMs = weigh(m.AvgLatencyMs, s.AvgLatencyMs)
+				m.AvgDNSMs = weigh(m.AvgDNSMs, s.AvgDNSMs)
+				m.AvgConnectMs = weigh(m.AvgConnectMs, s.AvgConnectMs)
+				m.AvgTLSMs = weigh(m.AvgTLSMs, s.AvgTLSMs)
+				if s.AvgServerMs != nil {
+					server := *s.AvgServerMs
+					if m.AvgServerMs != nil {
+						server = weigh(*m.AvgServerMs, server)
+					}
+					m.AvgServerMs = &server
+				}
+				m.LatencyCount = n
+			}
+			m.Total += s.Total
+			m.Up += s.Up
+			m.Degraded += s.Degraded
+			for category, count := range s.Failures {
+				m.Failures[category] += count
+			}
+			for status, count := range s.Statuses {
+				m.Statuses[status] += count
+			}
+			m.UptimeSeconds = math.Min(m.UptimeSeconds, s.UptimeSeconds)
+			if s.CertExpiry != "" && (m.CertExpiry == "" || parseRFC3339(s.CertExpiry).Before(parseRFC3339(m.CertExpiry))) {
+				m.CertExpiry, m.CertDaysLeft = s.CertExpiry, s.CertDaysLeft
+			}
+		}
+	}
+	summaries := make([]domainSummary, 0, len(merged))
+	for _, m := range merged {
+		if m.Total > 0 {
+			m.Availability = float64(m.Up) / float64(m.Total) * 100
+			if *confidence > 0 {
+				low, high := wilsonInterval(m.Up, m.Total, *confidence)
+				m.CILow, m.CIHigh = &low, &high
+			}
+		}
+		summaries = append(summaries, *m)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Domain < summaries[j].Domain })
+	return summaries
+}
+
+func parseRFC3339(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
+
+// Log the merged availability per domain after this instance's own summary
+func printMerged(w io.Writer, stats map[string]*Stats) {
+	if *compact || !havePeers() {
+		return
+	}
+	summaries := mergedSummaries(stats)
+	for _, s := range summaries {
+		if s.Total < *minSamples {
+			fmt.Fprintf(w, "merged: %s collecting data (%d/%d samples)\n", s.Domain, s.Total, *minSamples)
+			continue
+		}
+		fmt.Fprintf(w, "merged: %s has %d%% availability percentage over %d checks from %d instances\n",
+			s.Domain, roundAvailability(s.Availability), s.Total, s.Instances)
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// latency histogram bucket upper bounds in seconds (+Inf is implicit), from -latency-buckets
+var latencyBounds = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
+// Parse -latency-buckets: comma-separated millisecond bounds, positive and increasing
+func parseLatencyBuckets(spec string) ([]float64, error) {
+	var bounds []float64
+	for _, field := range strings.Split(spec, ",") {
+		ms, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket %q", field)
+		}
+		if ms <= 0 {
+			return nil, fmt.Errorf("bucket %gms must be positive", ms)
+		}
+		if len(bounds) > 0 && ms/1000 <= bounds[len(bounds)-1] {
+			return nil, fmt.Errorf("buckets must be increasing, got %gms after %gms", ms, bounds[len(bounds)-1]*1000)
+		}
+		bounds = append(bounds, ms/1000)
+	}
+	return bounds, nil
+}
+
+// cumulative latency histogram with the most recent exemplar per bucket
+type latencyHistogram struct {
+	counts    []uint64 // per bucket, last entry is +Inf; not cumulative
+	exemplars []exemplar
+	sum       float64
+	count     uint64
+}
+
+// latency sample linked to the trace that produced it
+type exemplar struct {
+	traceID string
+	value   float64
+	at      time.Time
+}
+
+func (stat *Stats) observeLatency(latency time.Duration, traceID string) {
+	h := &stat.latency
+	if h.counts == nil {
+		h.counts = make([]uint64, len(latencyBounds)+1)
+		h.exemplars = make([]exemplar, len(latencyBounds)+1)
+	}
+	stat.console[sort.Search(len(consoleBounds), func(i int) bool { return latency < consoleBounds[i] })]++
+	seconds := latency.Seconds()
+	i := sort.SearchFloat64s(latencyBounds, seconds) // first bound >= seconds
+	h.counts[i]++
+	h.sum += seconds
+	h.count++
+	if traceID != "" {
+		h.exemplars[i] = exemplar{traceID: traceID, value: seconds, at: time.Now()}
+	}
+}
+
+// label set for a domain's series, plus region when -region is set
+func domainLabels(domain string) string {
+	return fmt.Sprintf("domain=%q", domain) + regionLabel()
+}
+
+func regionLabel() string {
+	if *region == "" {
+		return ""
+	}
+	return fmt.Sprintf(",region=%q", *region)
+}
+
+// trace id from a W3C traceparent header: version-traceid-spanid-flags
+func traceID(traceparent string) string {
+	parts := strings.Split(traceparent, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 {
+		return ""
+	}
+	return parts[1]
+}
+
+// Serve /metrics (and the /stats and /events JSON) until the process exits
+func serveMetrics(addr string, stats map[string]*Stats) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		// Prometheus asks for OpenMetrics via Accept when it supports exemplars
+		openMetrics := strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text")
+		if openMetrics {
+			w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		}
+		writeMetrics(w, stats, openMetrics)
+	})
+	// same per-domain records as -summary-file, for tools polling the checker directly;
+	// ?merged=1 merges in peer instances' stats, which a POST pushes
+	mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			servePeerPush(w, r)
+			return
+		}
+		summaries := domainSummaries(stats)
+		if r.URL.Query().Get("merged") != "" {
+			summaries = mergedSummaries(stats)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(summaries)
+	})
+	mux.HandleFunc("/events", serveEvents)
+	mux.HandleFunc("/report", serveReport(stats))
+	log.Printf("Serving metrics on %s/metrics", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Fatalf("Error serving metrics: %v", err)
+	}
+}
+
+// Write stats in Prometheus text format, or OpenMetrics (with exemplars) when openMetrics is set
+func writeMetrics(w io.Writer, stats map[string]*Stats, openMetrics bool) {
+	stats = snapshotStats(stats)
+	domains := make([]string, 0, len(stats))
+	for domain := range stats {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	// OpenMetrics names counter families without the _total suffix
+	counterFamily := func(name string) string {
+		if openMetrics {
+			return strings.TrimSuffix(name, "_total")
+		}
+		return name
+	}
+	fmt.Fprintf(w, "# HELP %s Health checks run per domain.\n", counterFamily("health_check_requests_total"))
+	fmt.Fprintf(w, "# TYPE %s counter\n", counterFamily("health_check_requests_total"))
+	for _, domain := range domains {
+		fmt.Fprintf(w, "health_check_requests_total{%s} %d\n", domainLabels(domain), stats[domain].totalRequests)
+	}
+	fmt.Fprintf(w, "# HELP %s Health checks counted UP per domain.\n", counterFamily("health_check_up_total"))
+	fmt.Fprintf(w, "# TYPE %s counter\n", counterFamily("health_check_up_total"))
+	for _, domain := range domains {
+		fmt.Fprintf(w, "health_check_up_total{%s} %d\n", domainLabels(domain), stats[domain].upRequests)
+	}
+	fmt.Fprintf(w, "# HELP %s Health checks counted DEGRADED per domain (also counted UP).\n", counterFamily("health_check_degraded_total"))
+	fmt.Fprintf(w, "# TYPE %s counter\n", counterFamily("health_check_degraded_total"))
+	for _, domain := range domains {
+		fmt.Fprintf(w, "health_check_degraded_total{%s} %d\n", domainLabels(domain), stats[domain].degraded)
+	}
+	fmt.Fprintf(w, "# HELP %s Health checks counted DOWN per domain and failure category.\n", counterFamily("health_check_failures_total"))
+	fmt.Fprintf(w, "# TYPE %s counter\n", counterFamily("health_check_failures_total"))
+	for _, domain := range domains {
+		categories := make([]string, 0, len(stats[domain].failures))
+		for category := range stats[domain].failures {
+			categories = append(categories, category)
+		}
+		sort.Strings(categories)
+		for _, category := range categories {
+			fmt.Fprintf(w, "health_check_failures_total{%s,category=%q} %d\n", domainLabels(domain), category,
+				stats[domain].failures[category])
+		}
+	}
+	writeEndpointMetrics(w, counterFamily)
+	fmt.Fprintln(w, "# HELP health_check_latency_seconds Response latency per domain.")
+	fmt.Fprintln(w, "# TYPE health_check_latency_seconds histogram")
+	for _, domain := range domains {
+		h := stats[domain].latency
+		var cumulative uint64
+		for i := 0; i <= len(latencyBounds); i++ {
+			le := "+Inf"
+			if i < len(latencyBounds) {
+				le = strconv.FormatFloat(latencyBounds[i], 'g', -1, 64)
+			}
+			if h.counts != nil {
+				cumulative += h.counts[i]
+			}
+			fmt.Fprintf(w, "health_check_latency_seconds_bucket{%s,le=%q} %d", domainLabels(domain), le, cumulative)
+			if openMetrics && h.exemplars != nil && h.exemplars[i].traceID != "" {
+				e := h.exemplars[i]
+				fmt.Fprintf(w, " # {trace_id=%q} %g %.3f", e.traceID, e.value, float64(e.at.UnixMilli())/1000)
+			}
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "health_check_latency_seconds_sum{%s} %g\n", domainLabels(domain), h.sum)
+		fmt.Fprintf(w, "health_check_latency_seconds_count{%s} %d\n", domainLabels(domain), h.count)
+	}
+	if openMetrics {
+		fmt.Fprintln(w, "# EOF")
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// One canned response of the -mock server
+type mockFixture struct {
+	// "https://api.example.com/health" matches that scheme, host and path; "/health" any
+	URL     string            `yaml:"url"`
+	Method  string            `yaml:"method,omitempty"` // empty matches any method
+	Status  int               `yaml:"status,omitempty"` // default 200
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Body    string            `yaml:"body,omitempty"`
+	Delay   time.Duration     `yaml:"delay,omitempty"` // before responding, e.g. to trip the latency threshold
+	scheme  string
+	host    string
+	path    string
+}
+
+// addresses of the in-process -mock server every check dials, "" when not mocking:
+// one port for http and one standing in for https, so fixtures can tell them apart
+var mockAddr, mockHTTPSAddr string
+
+// Read the -mock fixtures file and serve it on a loopback port until exit
+func startMock(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var fixtures []mockFixture
+	if err := yaml.Unmarshal(data, &fixtures); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range fixtures {
+		fixture := &fixtures[i]
+		u, err := url.Parse(fixture.URL)
+		if err != nil || u.Path == "" {
+			return fmt.Errorf("%s: fixture %d: url %q must be a url or path", path, i+1, fixture.URL)
+		}
+		fixture.scheme, fixture.host, fixture.path = u.Scheme, u.Host, u.Path
+		if fixture.Status == 0 {
+			fixture.Status = http.StatusOK
+		}
+		if fixture.Status < 100 || fixture.Status > 999 {
+			return fmt.Errorf("%s: fixture %d: invalid status %d", path, i+1, fixture.Status)
+		}
+	}
+	serve := func(scheme string) (string, error) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return "", err
+		}
+		go http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, fixture := range fixtures {
+				if fixture.matches(scheme, r) {
+					fixture.serve(w, r)
+					return
+				}
+			}
+			http.Error(w, fmt.Sprintf("no mock fixture for %s %s://%s%s", r.Method, scheme, r.Host, r.URL.Path),
+				http.StatusNotFound)
+		}))
+		return listener.Addr().String(), nil
+	}
+	if mockAddr, err = serve("http"); err != nil {
+		return err
+	}
+	if mockHTTPSAddr, err = serve("https"); err != nil {
+		return err
+	}
+	log.Printf("Mocking all checks with %d fixtures from %s", len(fixtures), path)
+	return nil
+}
+
+func (fixture mockFixture) matches(scheme string, r *http.Request) bool {
+	return (fixture.Method == "" || strings.EqualFold(fixture.Method, r.Method)) &&
+		(fixture.scheme == "" || fixture.scheme == scheme) &&
+		(fixture.host == "" || strings.EqualFold(fixture.host, r.Host)) && fixture.path == r.URL.Path
+}
+
+func (fixture mockFixture) serve(w http.ResponseWriter, r *http.Request) {
+	if fixture.Delay > 0 {
+		select {
+		case <-time.After(fixture.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	for key, value := range fixture.Headers {
+		w.Header().Set(key, value)
+	}
+	w.WriteHeader(fixture.Status)
+	fmt.Fprint(w, fixture.Body)
+}
+
+// Dial the -mock server whatever the address
+func mockDial(ctx context.Context, network, address string) (net.Conn, error) {
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", mockAddr)
+}
+
+// Dial the -mock https port: the transport takes the plain connection as
+// already past the TLS handshake
+func mockDialTLS(ctx context.Context, network, address string) (net.Conn, error) {
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", mockHTTPSAddr)
+}
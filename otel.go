@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OTLP/HTTP with JSON encoding: spans buffered per cycle, sent with the
+// cycle's metrics to <-otlp-endpoint>/v1/traces and /v1/metrics
+
+// attribute value in the OTLP JSON mapping, e.g. {"stringValue": "..."}
+type otlpValue map[string]any
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+func stringAttr(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{"stringValue": value}}
+}
+
+// int64 values are strings in OTLP JSON
+func intAttr(key string, value int) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{"intValue": strconv.Itoa(value)}}
+}
+
+func doubleAttr(key string, value float64) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{"doubleValue": value}}
+}
+
+type otlpSpan struct {
+	TraceID    string          `json:"traceId"`
+	SpanID     string          `json:"spanId"`
+	Name       string          `json:"name"`
+	Kind       int             `json:"kind"` // 3 = client
+	Start      string          `json:"startTimeUnixNano"`
+	End        string          `json:"endTimeUnixNano"`
+	Attributes []otlpAttribute `json:"attributes"`
+	Status     struct {
+		Code    int    `json:"code"` // 1 = ok, 2 = error
+		Message string `json:"message,omitempty"`
+	} `json:"status"`
+}
+
+var (
+	spansMu sync.Mutex
+	spans   []otlpSpan // since the last export
+)
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// -otlp-endpoint: buffer a client span covering the check
+func recordSpan(endpoint Endpoint, result checkResult) {
+	if *otlpEndpoint == "" {
+		return
+	}
+	end := time.Now()
+	span := otlpSpan{
+		TraceID: randomHex(16),
+		SpanID:  randomHex(8),
+		Name:    "health check " + endpoint.Name,
+		Kind:    3,
+		Start:   unixNano(end.Add(-result.latency)),
+		End:     unixNano(end),
+		Attributes: []otlpAttribute{
+			stringAttr("health_check.endpoint", endpoint.Name),
+			stringAttr("health_check.domain", statsKey(endpoint)),
+			stringAttr("url.full", endpoint.target()),
+			doubleAttr("health_check.latency_ms", milliseconds(result.latency)),
+		},
+	}
+	// reuse the trace id the server echoed (traceparent), so the span joins its trace
+	if result.traceID != "" {
+		span.TraceID = result.traceID
+	}
+	if endpoint.Description != "" {
+		span.Attributes = append(span.Attributes, stringAttr("health_check.description", endpoint.Description))
+	}
+	if result.requestID != "" {
+		span.Attributes = append(span.Attributes, stringAttr("health_check.request_id", result.requestID))
+	}
+	if result.status != 0 {
+		span.Attributes = append(span.Attributes, intAttr("http.response.status_code", result.status))
+	}
+	span.Status.Code = 1
+	if !result.up {
+		span.Status.Code, span.Status.Message = 2, result.reason
+	}
+	spansMu.Lock()
+	spans = append(spans, span)
+	spansMu.Unlock()
+}
+
+// -otlp-endpoint: send the buffered spans and the current metrics
+func exportOTLP(stats map[string]*Stats) {
+	if *otlpEndpoint == "" {
+		return
+	}
+	spansMu.Lock()
+	batch := spans
+	spans = nil
+	spansMu.Unlock()
+	if len(batch) > 0 {
+		traces := map[string]any{"resourceSpans": []any{map[string]any{
+			"resource":   otlpResource(),
+			"scopeSpans": []any{map[string]any{"scope": otlpScope(), "spans": batch}},
+		}}}
+		if err := postOTLP("/v1/traces", traces); err != nil {
+			log.Printf("Error exporting OTLP traces: %v", err)
+		}
+	}
+	metrics := map[string]any{"resourceMetrics": []any{map[string]any{
+		"resource":     otlpResource(),
+		"scopeMetrics": []any{map[string]any{"scope": otlpScope(), "metrics": otlpMetrics(stats)}},
+	}}}
+	if err := postOTLP("/v1/metrics", metrics); err != nil {
+		log.Printf("Error exporting OTLP metrics: %v", err)
+	}
+}
+
+func otlpResource() map[string]any {
+	attributes := []otlpAttribute{stringAttr("service.name", "health-check")}
+	if *region != "" {
+		attributes = append(attributes, stringAttr("cloud.region", *region))
+	}
+	return map[string]any{"attributes": attributes}
+}
+
+func otlpScope() map[string]any {
+	return map[string]any{"name": "health-check"}
+}
+
+// per-domain request/up counters, availability gauge and latency histogram
+func otlpMetrics(stats map[string]*Stats) []any {
+	stats = snapshotStats(stats)
+	domains := make([]string, 0, len(stats))
+	for domain := range stats {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	statsMu.RLock()
+	start, now := unixNano(statsSince), unixNano(time.Now())
+	statsMu.RUnlock()
+	var requests, up, availability, latency []any
+	for _, domain := range domains {
+		stat := stats[domain]
+		attributes := []otlpAttribute{stringAttr("domain", domain)}
+		requests = append(requests, map[string]any{"attributes": attributes, "startTimeUnixNano": start,
+			"timeUnixNano": now, "asInt": strconv.Itoa(stat.totalRequests)})
+		up = append(up, map[string]any{"attributes": attributes, "startTimeUnixNano": start,
+			"timeUnixNano": now, "asInt": strconv.Itoa(stat.upRequests)})
+		if stat.totalRequests > 0 {
+			availability = append(availability, map[string]any{"attributes": attributes, "timeUnixNano": now,
+				"asDouble": float64(stat.upRequests) / float64(stat.totalRequests)})
+		}
+		if stat.latency.count > 0 {
+			counts := make([]string, len(stat.latency.counts))
+			for i, count := range stat.latency.counts {
+				counts[i] = strconv.FormatUint(count, 10)
+			}
+			latency = append(latency, map[string]any{"attributes": attributes, "startTimeUnixNano": start,
+				"timeUnixNano": now, "count": strconv.FormatUint(stat.latency.count, 10), "sum": stat.latency.sum,
+				"bucketCounts": counts, "explicitBounds": latencyBounds})
+		}
+	}
+	// aggregationTemporality 2 = cumulative
+	sum := func(name, description string, points []any) map[string]any {
+		return map[string]any{"name": name, "description": description, "unit": "1",
+			"sum": map[string]any{"dataPoints": points, "aggregationTemporality": 2, "isMonotonic": true}}
+	}
+	return []any{
+		sum("health_check.requests", "Health check requests by domain.", requests),
+		sum("health_check.up_requests", "Health check requests that were UP, by domain.", up),
+		map[string]any{"name": "health_check.availability", "description": "Fraction of requests that were UP, by domain.",
+			"unit": "1", "gauge": map[string]any{"dataPoints": availability}},
+		map[string]any{"name": "health_check.latency", "description": "Health check response latency.", "unit": "s",
+			"histogram": map[string]any{"dataPoints": latency, "aggregationTemporality": 2}},
+	}
+}
+
+func postOTLP(path string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	url := strings.TrimSuffix(*otlpEndpoint, "/") + path
+	resp, err := configClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return nil
+}
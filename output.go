@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Writer prefixing every line, e.g. with the -region tag
+type prefixWriter struct {
+	w       io.Writer
+	prefix  string
+	midLine bool // last write didn't end with a newline
+}
+
+func (pw *prefixWriter) Write(p []byte) (int, error) {
+	var buf []byte
+	for _, c := range p {
+		if !pw.midLine {
+			buf = append(buf, pw.prefix...)
+		}
+		buf = append(buf, c)
+		pw.midLine = c != '\n'
+	}
+	if _, err := pw.w.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// console latency histogram bucket upper bounds (last bucket is open-ended)
+var consoleBounds = [...]time.Duration{100 * time.Millisecond, 300 * time.Millisecond, 500 * time.Millisecond}
+
+// widest histogram bar in characters
+const histogramWidth = 40
+
+// Print an ASCII bar per latency bucket, scaled to the largest bucket
+func printHistogram(w io.Writer, counts [len(consoleBounds) + 1]int) {
+	largest := 0
+	for _, count := range counts {
+		largest = max(largest, count)
+	}
+	for i, count := range counts {
+		var label string
+		switch {
+		case i == len(consoleBounds):
+			label = fmt.Sprintf(">%dms", consoleBounds[i-1].Milliseconds())
+		case i == 0:
+			label = fmt.Sprintf("0-%dms", consoleBounds[i].Milliseconds())
+		default:
+			label = fmt.Sprintf("%d-%dms", consoleBounds[i-1].Milliseconds(), consoleBounds[i].Milliseconds())
+		}
+		bar := 0
+		if largest > 0 {
+			bar = count * histogramWidth / largest
+		}
+		fmt.Fprintf(w, "    %10s | %s %d\n", label, strings.Repeat("#", bar), count)
+	}
+}
+
+// Append-only output file, optionally rotated once it exceeds maxSize bytes
+type rotatingFile struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64 // 0 disables rotation
+	file    *os.File
+	size    int64
+}
+
+// Open path for appending, creating it if it does not exist
+func openRotatingFile(path string, maxSize int64) (*rotatingFile, error) {
+	rf := &rotatingFile{path: path, maxSize: maxSize}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *rotatingFile) open() error {
+	file, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	rf.file = file
+	rf.size = info.Size()
+	return nil
+}
+
+// Write appends p, rotating first when p would push the file over maxSize
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.file == nil { // closed by a rotation that couldn't reopen it
+		if err := rf.open(); err != nil {
+			return 0, err
+		}
+	}
+	if rf.maxSize > 0 && rf.size > 0 && rf.size+int64(len(p)) > rf.maxSize {
+		if err := rf.rotate(); err != nil {
+			if rf.file == nil {
+				return 0, err
+			}
+			log.Printf("Error rotating output: %v", err) // keep writing to the unrotated file
+		}
+	}
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// keep a single previous generation at <path>.1
+func (rf *rotatingFile) rotate() error {
+	err := rf.file.Close()
+	rf.file = nil
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(rf.path, rf.path+".1"); err != nil {
+		// reopen the unrotated file so later writes still land somewhere
+		rf.open()
+		return fmt.Errorf("rotating %s: %w", rf.path, err)
+	}
+	return rf.open()
+}
+
+func (rf *rotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	return rf.file.Close()
+}
+
+// cumulative stats for one domain in the shutdown summary
+type domainSummary struct {
+	Domain        string         `json:"domain"`
+	Region        string         `json:"region,omitempty"`
+	Instances     int            `json:"instances,omitempty"`
+	Total         int            `json:"total"`
+	Up            int            `json:"up"`
+	Degraded      int            `json:"degraded,omitempty"`         // DEGRADED results, included in up
+	Availability  float64        `json:"availability"`               // percent
+	CILow         *float64       `json:"availability_low,omitempty"` // Wilson interval, with -confidence
+	CIHigh        *float64       `json:"availability_high,omitempty"`
+	AvgLatencyMs  float64        `json:"avg_latency_ms"`
+	LatencyCount  uint64         `json:"latency_count"`
+	AvgDNSMs      float64        `json:"avg_dns_ms"`
+	AvgConnectMs  float64        `json:"avg_connect_ms"`
+	AvgTLSMs      float64        `json:"avg_tls_ms"`
+	AvgServerMs   *float64       `json:"avg_server_ms,omitempty"`
+	UptimeSeconds float64        `json:"uptime_seconds"`        // 0 while DOWN
+	CertExpiry    string         `json:"cert_expiry,omitempty"` // RFC 3339, HTTPS only
+	CertDaysLeft  *int           `json:"cert_days_left,omitempty"`
+	Failures      map[string]int `json:"failures,omitempty"` // DOWN results by category
+	Statuses      map[int]int    `json:"statuses,omitempty"` // responses by HTTP status code
+}
+
+// Write cumulative per-domain stats to path as a JSON array sorted by domain
+func writeSummaryFile(path string, stats map[string]*Stats) error {
+	data, err := json.MarshalIndent(domainSummaries(stats), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// cumulative per-domain stats sorted by domain
+func domainSummaries(stats map[string]*Stats) []domainSummary {
+	stats = snapshotStats(stats)
+	summaries := make([]domainSummary, 0, len(stats))
+	for domain, stat := range stats {
+		summary := domainSummary{Domain: domain, Region: *region, Total: stat.totalRequests, Up: stat.upRequests,
+			Degraded: stat.degraded, Failures: stat.failures, Statuses: stat.statuses}
+		if stat.totalRequests > 0 {
+			summary.Availability = float64(stat.upRequests) / float64(stat.totalRequests) * 100
+			if *confidence > 0 {
+				low, high := wilsonInterval(stat.upRequests, stat.totalRequests, *confidence)
+				summary.CILow, summary.CIHigh = &low, &high
+			}
+		}
+		if stat.latency.count > 0 {
+			summary.AvgLatencyMs = stat.latency.sum / float64(stat.latency.count) * 1000
+			summary.LatencyCount = stat.latency.count
+		}
+		phases := stat.phases.average()
+		summary.AvgDNSMs = milliseconds(phases.dns)
+		summary.AvgConnectMs = milliseconds(phases.connect)
+		summary.AvgTLSMs = milliseconds(phases.tls)
+		if stat.server.count > 0 {
+			server, _ := stat.server.average()
+			ms := milliseconds(server)
+			summary.AvgServerMs = &ms
+		}
+		if !stat.certExpiry.IsZero() {
+			days := daysUntil(stat.certExpiry)
+			summary.CertExpiry = stat.certExpiry.Format(time.RFC3339)
+			summary.CertDaysLeft = &days
+		}
+		if !stat.upSince.IsZero() {
+			summary.UptimeSeconds = time.Since(stat.upSince).Seconds()
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Domain < summaries[j].Domain })
+	return summaries
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+)
+
+// PagerDuty Events API v2 event
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"` // trigger or resolve
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"` // trigger only
+}
+
+type pagerDutyPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+// domains with an open incident, so each is triggered and resolved once per outage
+var pagerDutyOpen = make(map[string]bool)
+
+// routing key from -pagerduty-routing-key, else $PAGERDUTY_ROUTING_KEY
+func pagerDutyRoutingKey() string {
+	if *pagerDutyKey != "" {
+		return *pagerDutyKey
+	}
+	return os.Getenv("PAGERDUTY_ROUTING_KEY")
+}
+
+// After each cycle: trigger an incident (dedup key = domain) for every DOWN domain
+// whose availability fell below -pagerduty-threshold, and resolve it as soon as the
+// domain is UP again - cumulative availability can stay below the threshold long
+// after a recovery. Failed sends are retried on the next cycle.
+func alertPagerDuty(stats map[string]*Stats) {
+	key := pagerDutyRoutingKey()
+	if key == "" {
+		return
+	}
+	stats = snapshotStats(stats)
+	domains := make([]string, 0, len(stats))
+	for domain := range stats {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	for _, domain := range domains {
+		stat := stats[domain]
+		if stat.totalRequests == 0 || stat.totalRequests < *minSamples {
+			continue
+		}
+		availability := float64(stat.upRequests) / float64(stat.totalRequests) * 100
+		up := !stat.upSince.IsZero()
+		open := pagerDutyOpen[domain]
+		trigger := !open && !up && availability < *pagerDutyThreshold
+		if !trigger && !(open && up) {
+			continue
+		}
+		event := pagerDutyEvent{RoutingKey: key, EventAction: "resolve", DedupKey: domain}
+		if trigger {
+			event.EventAction = "trigger"
+			event.Payload = &pagerDutyPayload{
+				Summary: fmt.Sprintf("%s availability %.1f%% is below %g%%", domain, availability,
+					*pagerDutyThreshold),
+				Source:   domain,
+				Severity: "critical",
+				CustomDetails: map[string]any{
+					"availability": availability,
+					"total":        stat.totalRequests,
+					"up":           stat.upRequests,
+					"last_error":   stat.lastError,
+					"region":       *region,
+				},
+			}
+		}
+		if err := postPagerDuty(event); err != nil {
+			log.Printf("Error sending PagerDuty %s for %s: %v", event.EventAction, domain, err)
+			continue
+		}
+		pagerDutyOpen[domain] = trigger
+	}
+}
+
+// Resolve domain's open incident, if any: it left the config on reload
+func resolvePagerDuty(domain string) {
+	if !pagerDutyOpen[domain] {
+		return
+	}
+	event := pagerDutyEvent{RoutingKey: pagerDutyRoutingKey(), EventAction: "resolve", DedupKey: domain}
+	if err := postPagerDuty(event); err != nil {
+		log.Printf("Error sending PagerDuty resolve for %s: %v", domain, err)
+		return
+	}
+	delete(pagerDutyOpen, domain)
+}
+
+func postPagerDuty(event pagerDutyEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	resp, err := configClient.Post(*pagerDutyURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", *pagerDutyURL, resp.Status)
+	}
+	return nil
+}
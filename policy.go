@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// M-of-N smoothing: an endpoint counts UP while at least Required of its last
+// Window raw checks succeeded. Checks not yet run count as successes, so a new
+// endpoint isn't DOWN before its window fills.
+type SuccessPolicy struct {
+	Required int `yaml:"required"`
+	Window   int `yaml:"window"`
+}
+
+func (policy *SuccessPolicy) validate(name string) error {
+	if policy == nil {
+		return nil
+	}
+	if policy.Window < 1 || policy.Required < 1 || policy.Required > policy.Window {
+		return fmt.Errorf("%s: success_policy needs 1 <= required <= window", name)
+	}
+	return nil
+}
+
+var (
+	outcomesMu sync.Mutex
+	outcomes   = make(map[string][]bool) // last raw results by endpoint name, oldest first
+)
+
+// Smooth result over the endpoint's recent raw outcomes (no-op without success_policy)
+func applySuccessPolicy(endpoint Endpoint, result checkResult) checkResult {
+	policy := endpoint.SuccessPolicy
+	if policy == nil {
+		return result
+	}
+	outcomesMu.Lock()
+	defer outcomesMu.Unlock()
+	recent := append(outcomes[endpoint.Name], result.up)
+	if len(recent) > policy.Window {
+		recent = recent[len(recent)-policy.Window:]
+	}
+	outcomes[endpoint.Name] = recent
+	failures := 0
+	for _, up := range recent {
+		if !up {
+			failures++
+		}
+	}
+	smoothedUp := failures <= policy.Window-policy.Required
+	if smoothedUp && !result.up {
+		result.up = true
+		result.reason = ""
+	} else if !smoothedUp && result.up {
+		result.up = false
+		result.reason = fmt.Sprintf("%d of last %d checks failed (success_policy %d of %d)",
+			failures, len(recent), policy.Required, policy.Window)
+	} else if !smoothedUp {
+		result.reason = fmt.Sprintf("%s (%d of last %d checks failed)", result.reason, failures, len(recent))
+	}
+	return result
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	_ "net/http/pprof" // registers /debug/pprof/ on http.DefaultServeMux
+)
+
+// Serve net/http/pprof on its own address, separate from the metrics server
+func servePprof(addr string) {
+	log.Printf("Serving pprof on %s/debug/pprof/", addr)
+	if err := http.ListenAndServe(addr, nil); err != nil {
+		log.Fatalf("Error serving pprof: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+
+	"health-check/resultpb"
+)
+
+// -protobuf-out: each cycle's results as length-delimited resultpb.CheckResult records,
+// each a varint byte length then the message - the framing of Java's writeDelimitedTo
+// and Go's protodelim.
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative resultpb/result.proto
+
+var (
+	protobufMu      sync.Mutex
+	protobufRecords bytes.Buffer // since the last write
+	protobufSink    func([]byte) error
+)
+
+// "-" for stdout, else a file (or named pipe) appended to
+func newProtobufSink(dest string) (func([]byte) error, error) {
+	out := os.Stdout
+	if dest != "-" {
+		var err error
+		if out, err = os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+			return nil, err
+		}
+	}
+	return func(batch []byte) error {
+		_, err := out.Write(batch)
+		return err
+	}, nil
+}
+
+// Buffer a result as a delimited record until the cycle's write
+func recordProtobuf(event resultEvent) {
+	if protobufSink == nil {
+		return
+	}
+	protobufMu.Lock()
+	defer protobufMu.Unlock()
+	if _, err := protodelim.MarshalTo(&protobufRecords, checkResultMessage(event)); err != nil {
+		log.Printf("Error encoding protobuf result for %s: %v", event.Name, err)
+	}
+}
+
+// Write the cycle's records in one batch, so a reader never sees a partial cycle
+func writeProtobuf() {
+	if protobufSink == nil {
+		return
+	}
+	protobufMu.Lock()
+	batch := bytes.Clone(protobufRecords.Bytes())
+	protobufRecords.Reset()
+	protobufMu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+	if err := protobufSink(batch); err != nil {
+		log.Printf("Error writing protobuf results: %v", err)
+	}
+}
+
+// the JSON result event as a CheckResult message
+func checkResultMessage(e resultEvent) *resultpb.CheckResult {
+	return &resultpb.CheckResult{
+		TimeUnixNano:        uint64(e.Time.UnixNano()),
+		Name:                e.Name,
+		Description:         e.Description,
+		Url:                 e.URL,
+		Domain:              e.Domain,
+		Region:              e.Region,
+		RequestId:           e.RequestID,
+		Up:                  e.Up,
+		Degraded:            e.Degraded,
+		Status:              int32(e.Status),
+		LatencyMs:           e.LatencyMs,
+		DnsMs:               e.DNSMs,
+		ConnectMs:           e.ConnectMs,
+		TlsMs:               e.TLSMs,
+		ServerMs:            e.ServerMs,
+		Reason:              e.Reason,
+		Category:            e.Category,
+		Maintenance:         e.Maintenance,
+		BodyBytes:           int64(e.BodyBytes),
+		ResponseBytes:       e.ResponseBytes,
+		Allow:               e.Allow,
+		Attempts:            int32(e.Attempts),
+		AttemptLatencyMs:    e.AttemptLatencyMs,
+		TotalLatencyMs:      e.TotalLatencyMs,
+		RetryAfterMs:        e.RetryAfterMs,
+		RetryAfterRecovered: e.Recovered,
+	}
+}
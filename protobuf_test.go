@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+
+	"health-check/resultpb"
+)
+
+// -protobuf-out records decode with the generated types into what was recorded
+func TestProtobufOutRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.pb")
+	sink, err := newProtobufSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { protobufSink = nil }()
+	protobufSink = sink
+	serverMs, recovered := 12.5, false
+	events := []resultEvent{
+		{Time: time.Unix(1700000000, 5), Name: "login", URL: "https://api.example.com/login", Domain: "api.example.com",
+			Up: true, Status: 200, LatencyMs: 42.5, DNSMs: 1.5, ServerMs: &serverMs, Allow: []string{"GET", "OPTIONS"}},
+		{Time: time.Unix(1700000001, 0), Name: "orders", URL: "https://api.example.com/orders", Domain: "api.example.com",
+			Status: 503, Reason: "not recovered 1s after status 503 with Retry-After (status 503)", Category: "retry_after",
+			Attempts: 2, RetryAfterMs: 1000, Recovered: &recovered},
+	}
+	for _, event := range events {
+		recordProtobuf(event)
+	}
+	writeProtobuf()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	reader := bufio.NewReader(file)
+	for i, event := range events {
+		var got resultpb.CheckResult
+		if err := protodelim.UnmarshalFrom(reader, &got); err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+		if want := checkResultMessage(event); !proto.Equal(&got, want) {
+			t.Errorf("record %d:\ngot  %v\nwant %v", i, &got, want)
+		}
+	}
+	var extra resultpb.CheckResult
+	if err := protodelim.UnmarshalFrom(reader, &extra); !errors.Is(err, io.EOF) {
+		t.Errorf("after %d records: got %v, want EOF", len(events), err)
+	}
+	if got := checkResultMessage(events[1]); got.GetRetryAfterRecovered() || got.RetryAfterRecovered == nil || got.ServerMs != nil {
+		t.Errorf("optional fields: retry_after_recovered %v, server_ms %v", got.RetryAfterRecovered, got.ServerMs)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// largest random_body allowed, to keep a typo from allocating gigabytes per check
+const maxRandomBody = 64 << 20
+
+// Body of a random size in [Min, Max] bytes per request, made of Content
+// repeated (and cut to size); "x" when Content is empty
+type RandomBody struct {
+	Min     int    `yaml:"min"`
+	Max     int    `yaml:"max"`
+	Content string `yaml:"content,omitempty"`
+}
+
+func (cfg *RandomBody) validate(endpoint Endpoint) error {
+	if cfg == nil {
+		return nil
+	}
+	if endpoint.Body != "" {
+		return fmt.Errorf("%s: use either body or random_body", endpoint.Name)
+	}
+	if cfg.Min < 0 || cfg.Max < cfg.Min || cfg.Max > maxRandomBody {
+		return fmt.Errorf("%s: random_body needs 0 <= min <= max <= %d", endpoint.Name, maxRandomBody)
+	}
+	return nil
+}
+
+func (cfg *RandomBody) generate() string {
+	size := cfg.Min + rand.Intn(cfg.Max-cfg.Min+1)
+	content := cfg.Content
+	if content == "" {
+		content = "x"
+	}
+	return strings.Repeat(content, size/len(content)+1)[:size]
+}
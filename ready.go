@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// pause between -wait-for-ready rounds
+const readyInterval = 2 * time.Second
+
+// -wait-for-ready: check the endpoints not yet UP (dependencies first) every
+// readyInterval until each has been UP once, or timeout passes. Returns the
+// process exit code: 0 when all came up, 1 otherwise (listing the stragglers).
+func waitForReady(w io.Writer, endpoints []Endpoint, timeout time.Duration) int {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	endpoints = enabledEndpoints(endpoints)
+	start := time.Now()
+	ready := make(map[string]checkResult, len(endpoints)) // UP once, with its extracted values
+	last := make(map[string]checkResult, len(endpoints))  // latest result of those still pending
+	for {
+		for _, i := range dependencyOrder(endpoints) {
+			endpoint := endpoints[i]
+			if _, ok := ready[endpoint.Name]; ok {
+				continue
+			}
+			deps := make(map[string]map[string]string)
+			var waiting string
+			for _, name := range dependencies(endpoint) {
+				dep, ok := ready[name]
+				if !ok {
+					waiting = name
+					break
+				}
+				deps[name] = dep.extracted
+			}
+			if waiting != "" {
+				last[endpoint.Name] = checkResult{reason: fmt.Sprintf("waiting for dependency %s", waiting)}
+				continue
+			}
+			result := safeCheck(ctx, endpoint, deps)
+			if result.up {
+				ready[endpoint.Name] = result
+				delete(last, endpoint.Name)
+				fmt.Fprintf(w, "%s is UP after %s\n", endpoint.Name, time.Since(start).Round(time.Millisecond))
+			} else {
+				last[endpoint.Name] = result
+			}
+		}
+		if len(ready) == len(endpoints) {
+			fmt.Fprintf(w, "All %d endpoints ready after %s\n", len(endpoints), time.Since(start).Round(time.Millisecond))
+			return 0
+		}
+		select {
+		case <-time.After(readyInterval):
+		case <-ctx.Done():
+			fmt.Fprintf(w, "Timed out after %s; %d of %d endpoints never came UP:\n", timeout, len(last), len(endpoints))
+			for _, endpoint := range endpoints {
+				if result, pending := last[endpoint.Name]; pending {
+					fmt.Fprintf(w, "    %s (%s): %s\n", describe(endpoint), endpoint.target(), result.reason)
+				}
+			}
+			return 1
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// same limit as the net/http default policy
+const maxFollowedRedirects = 10
+
+type redirectChainKey struct{}
+
+// urls visited after the original request, in order
+type redirectChain struct {
+	urls []string
+}
+
+// attach a redirect recorder to ctx, filled in by recordRedirect
+func withRedirectChain(ctx context.Context) (context.Context, *redirectChain) {
+	chain := &redirectChain{}
+	return context.WithValue(ctx, redirectChainKey{}, chain), chain
+}
+
+// http.Client.CheckRedirect: record each hop, then apply the default limit
+func recordRedirect(req *http.Request, via []*http.Request) error {
+	if chain, ok := req.Context().Value(redirectChainKey{}).(*redirectChain); ok {
+		chain.urls = append(chain.urls, req.URL.String())
+	}
+	if len(via) >= maxFollowedRedirects {
+		return errors.New("stopped after 10 redirects")
+	}
+	return nil
+}
+
+// why the redirect chain breaks the endpoint's expectations, or "" if it doesn't
+func redirectMismatch(endpoint Endpoint, chain []string, finalURL string) string {
+	if endpoint.MaxRedirects != nil && len(chain) > *endpoint.MaxRedirects {
+		return fmt.Sprintf("%d redirects exceeds max_redirects %d", len(chain), *endpoint.MaxRedirects)
+	}
+	if endpoint.ExpectFinalURL != "" && finalURL != endpoint.ExpectFinalURL {
+		return fmt.Sprintf("final url %s, expected %s", finalURL, endpoint.ExpectFinalURL)
+	}
+	if endpoint.ExpectHTTPSRedirect {
+		if len(chain) == 0 {
+			return "no redirect to https"
+		}
+		if !strings.HasPrefix(chain[0], "https://") {
+			return fmt.Sprintf("redirected to %s, expected https", chain[0])
+		}
+	}
+	return ""
+}
+
+// expect_https_redirect only makes sense for plain http urls
+func validateHTTPSRedirect(endpoint Endpoint) error {
+	if !endpoint.ExpectHTTPSRedirect {
+		return nil
+	}
+	for _, target := range append([]string{endpoint.URL}, endpoint.URLs...) {
+		if target != "" && !strings.HasPrefix(target, "http://") {
+			return fmt.Errorf("%s: expect_https_redirect needs an http:// url, got %s", endpoint.Name, target)
+		}
+	}
+	return nil
+}
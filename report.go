@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// only -report format so far
+const reportMarkdown = "markdown"
+
+// pipes would end a table cell, newlines the row
+var markdownCellEscaper = strings.NewReplacer("|", `\|`, "\n", " ", "\r", "")
+
+// Markdown report of the cumulative stats for a wiki page, PR comment or incident review:
+// the stats period, then a table of per-domain status, availability, checks and latency
+func writeMarkdownReport(w io.Writer, stats map[string]*Stats) {
+	statsMu.RLock()
+	since := statsSince
+	statsMu.RUnlock()
+	stats = snapshotStats(stats)
+	now := time.Now()
+	fmt.Fprintln(w, "## Health check report")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%s to %s (%s)", since.Format(time.RFC3339), now.Format(time.RFC3339), now.Sub(since).Round(time.Second))
+	if *region != "" {
+		fmt.Fprintf(w, ", region %s", markdownCellEscaper.Replace(*region))
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Domain | Status | Availability | Checks | Avg latency | p95 latency | Last error |")
+	fmt.Fprintln(w, "| --- | --- | ---: | ---: | ---: | ---: | --- |")
+	domains := make([]string, 0, len(stats))
+	for domain := range stats {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	for _, domain := range domains {
+		stat := stats[domain]
+		status, availability, avg, p95 := "NO DATA", "-", "-", "-"
+		if stat.totalRequests > 0 {
+			status = "DOWN"
+			if !stat.upSince.IsZero() {
+				status = "UP"
+			}
+			availability = fmt.Sprintf("%.2f%%", float64(stat.upRequests)/float64(stat.totalRequests)*100)
+		}
+		if stat.latency.count > 0 {
+			avg = seconds(stat.latency.sum / float64(stat.latency.count)).String()
+		}
+		if len(stat.recent) > 0 {
+			p95 = percentile(stat.recent, 95).Round(time.Millisecond).String()
+		}
+		lastError := "-"
+		if stat.lastError != "" {
+			lastError = fmt.Sprintf("%s (%s)", stat.lastError, stat.lastErrorAt.Format(time.RFC3339))
+		}
+		fmt.Fprintf(w, "| %s | %s | %s | %d | %s | %s | %s |\n", markdownCellEscaper.Replace(domain), status,
+			availability, stat.totalRequests, avg, p95, markdownCellEscaper.Replace(lastError))
+	}
+}
+
+// -report on exit: write the report to -report-file, "-" for stdout
+func writeReport(path string, stats map[string]*Stats) error {
+	if path == "-" {
+		writeMarkdownReport(os.Stdout, stats)
+		return nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	writeMarkdownReport(file, stats)
+	return file.Close()
+}
+
+// GET /report on -metrics-addr: the same report on demand
+func serveReport(stats map[string]*Stats) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		writeMarkdownReport(w, stats)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// random (version 4) UUID, e.g. 9b2c4f0e-3a1d-4c7e-8f5a-6e2b1d0c9a47
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// sendCheck with a fresh -request-id-header value, recorded on the result so the
+// probe can be found in server logs. An endpoint setting the header itself keeps its value.
+func sendWithRequestID(ctx context.Context, endpoint Endpoint, deps map[string]map[string]string) checkResult {
+	if *requestIDHeader == "" || endpoint.Type == typeDNS || hasHeader(endpoint.Headers, *requestIDHeader) {
+		return sendCheck(ctx, endpoint, deps)
+	}
+	id := newRequestID()
+	headers := make(map[string]string, len(endpoint.Headers)+1)
+	for k, v := range endpoint.Headers {
+		headers[k] = v
+	}
+	headers[*requestIDHeader] = id
+	endpoint.Headers = headers
+	result := sendCheck(ctx, endpoint, deps)
+	result.requestID = id
+	if *verbose && !result.up {
+		log.Printf("%s is DOWN (%s), %s: %s", describe(endpoint), result.reason, *requestIDHeader, id)
+	}
+	return result
+}
+
+// header names are case-insensitive
+func hasHeader(headers map[string]string, name string) bool {
+	for k := range headers {
+		if http.CanonicalHeaderKey(k) == http.CanonicalHeaderKey(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,356 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: result.proto
+
+package resultpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CheckResult struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	TimeUnixNano        uint64                 `protobuf:"fixed64,1,opt,name=time_unix_nano,json=timeUnixNano,proto3" json:"time_unix_nano,omitempty"`
+	Name                string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description         string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Url                 string                 `protobuf:"bytes,4,opt,name=url,proto3" json:"url,omitempty"`
+	Domain              string                 `protobuf:"bytes,5,opt,name=domain,proto3" json:"domain,omitempty"`
+	Region              string                 `protobuf:"bytes,6,opt,name=region,proto3" json:"region,omitempty"`
+	RequestId           string                 `protobuf:"bytes,7,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Up                  bool                   `protobuf:"varint,8,opt,name=up,proto3" json:"up,omitempty"`
+	Degraded            bool                   `protobuf:"varint,9,opt,name=degraded,proto3" json:"degraded,omitempty"`
+	Status              int32                  `protobuf:"varint,10,opt,name=status,proto3" json:"status,omitempty"`
+	LatencyMs           float64                `protobuf:"fixed64,11,opt,name=latency_ms,json=latencyMs,proto3" json:"latency_ms,omitempty"`
+	DnsMs               float64                `protobuf:"fixed64,12,opt,name=dns_ms,json=dnsMs,proto3" json:"dns_ms,omitempty"`
+	ConnectMs           float64                `protobuf:"fixed64,13,opt,name=connect_ms,json=connectMs,proto3" json:"connect_ms,omitempty"`
+	TlsMs               float64                `protobuf:"fixed64,14,opt,name=tls_ms,json=tlsMs,proto3" json:"tls_ms,omitempty"`
+	ServerMs            *float64               `protobuf:"fixed64,15,opt,name=server_ms,json=serverMs,proto3,oneof" json:"server_ms,omitempty"`
+	Reason              string                 `protobuf:"bytes,16,opt,name=reason,proto3" json:"reason,omitempty"`
+	Category            string                 `protobuf:"bytes,17,opt,name=category,proto3" json:"category,omitempty"`
+	Maintenance         bool                   `protobuf:"varint,18,opt,name=maintenance,proto3" json:"maintenance,omitempty"`
+	BodyBytes           int64                  `protobuf:"varint,19,opt,name=body_bytes,json=bodyBytes,proto3" json:"body_bytes,omitempty"`
+	ResponseBytes       int64                  `protobuf:"varint,20,opt,name=response_bytes,json=responseBytes,proto3" json:"response_bytes,omitempty"`
+	Allow               []string               `protobuf:"bytes,21,rep,name=allow,proto3" json:"allow,omitempty"`
+	Attempts            int32                  `protobuf:"varint,22,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	AttemptLatencyMs    float64                `protobuf:"fixed64,23,opt,name=attempt_latency_ms,json=attemptLatencyMs,proto3" json:"attempt_latency_ms,omitempty"`
+	TotalLatencyMs      float64                `protobuf:"fixed64,24,opt,name=total_latency_ms,json=totalLatencyMs,proto3" json:"total_latency_ms,omitempty"`
+	RetryAfterMs        float64                `protobuf:"fixed64,25,opt,name=retry_after_ms,json=retryAfterMs,proto3" json:"retry_after_ms,omitempty"`
+	RetryAfterRecovered *bool                  `protobuf:"varint,26,opt,name=retry_after_recovered,json=retryAfterRecovered,proto3,oneof" json:"retry_after_recovered,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *CheckResult) Reset() {
+	*x = CheckResult{}
+	mi := &file_result_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckResult) ProtoMessage() {}
+
+func (x *CheckResult) ProtoReflect() protoreflect.Message {
+	mi := &file_result_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckResult.ProtoReflect.Descriptor instead.
+func (*CheckResult) Descriptor() ([]byte, []int) {
+	return file_result_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CheckResult) GetTimeUnixNano() uint64 {
+	if x != nil {
+		return x.TimeUnixNano
+	}
+	return 0
+}
+
+func (x *CheckResult) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CheckResult) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CheckResult) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *CheckResult) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *CheckResult) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *CheckResult) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *CheckResult) GetUp() bool {
+	if x != nil {
+		return x.Up
+	}
+	return false
+}
+
+func (x *CheckResult) GetDegraded() bool {
+	if x != nil {
+		return x.Degraded
+	}
+	return false
+}
+
+func (x *CheckResult) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *CheckResult) GetLatencyMs() float64 {
+	if x != nil {
+		return x.LatencyMs
+	}
+	return 0
+}
+
+func (x *CheckResult) GetDnsMs() float64 {
+	if x != nil {
+		return x.DnsMs
+	}
+	return 0
+}
+
+func (x *CheckResult) GetConnectMs() float64 {
+	if x != nil {
+		return x.ConnectMs
+	}
+	return 0
+}
+
+func (x *CheckResult) GetTlsMs() float64 {
+	if x != nil {
+		return x.TlsMs
+	}
+	return 0
+}
+
+func (x *CheckResult) GetServerMs() float64 {
+	if x != nil && x.ServerMs != nil {
+		return *x.ServerMs
+	}
+	return 0
+}
+
+func (x *CheckResult) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *CheckResult) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *CheckResult) GetMaintenance() bool {
+	if x != nil {
+		return x.Maintenance
+	}
+	return false
+}
+
+func (x *CheckResult) GetBodyBytes() int64 {
+	if x != nil {
+		return x.BodyBytes
+	}
+	return 0
+}
+
+func (x *CheckResult) GetResponseBytes() int64 {
+	if x != nil {
+		return x.ResponseBytes
+	}
+	return 0
+}
+
+func (x *CheckResult) GetAllow() []string {
+	if x != nil {
+		return x.Allow
+	}
+	return nil
+}
+
+func (x *CheckResult) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *CheckResult) GetAttemptLatencyMs() float64 {
+	if x != nil {
+		return x.AttemptLatencyMs
+	}
+	return 0
+}
+
+func (x *CheckResult) GetTotalLatencyMs() float64 {
+	if x != nil {
+		return x.TotalLatencyMs
+	}
+	return 0
+}
+
+func (x *CheckResult) GetRetryAfterMs() float64 {
+	if x != nil {
+		return x.RetryAfterMs
+	}
+	return 0
+}
+
+func (x *CheckResult) GetRetryAfterRecovered() bool {
+	if x != nil && x.RetryAfterRecovered != nil {
+		return *x.RetryAfterRecovered
+	}
+	return false
+}
+
+var File_result_proto protoreflect.FileDescriptor
+
+const file_result_proto_rawDesc = "" +
+	"\n" +
+	"\fresult.proto\x12\vhealthcheck\"\xc9\x06\n" +
+	"\vCheckResult\x12$\n" +
+	"\x0etime_unix_nano\x18\x01 \x01(\x06R\ftimeUnixNano\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x10\n" +
+	"\x03url\x18\x04 \x01(\tR\x03url\x12\x16\n" +
+	"\x06domain\x18\x05 \x01(\tR\x06domain\x12\x16\n" +
+	"\x06region\x18\x06 \x01(\tR\x06region\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\a \x01(\tR\trequestId\x12\x0e\n" +
+	"\x02up\x18\b \x01(\bR\x02up\x12\x1a\n" +
+	"\bdegraded\x18\t \x01(\bR\bdegraded\x12\x16\n" +
+	"\x06status\x18\n" +
+	" \x01(\x05R\x06status\x12\x1d\n" +
+	"\n" +
+	"latency_ms\x18\v \x01(\x01R\tlatencyMs\x12\x15\n" +
+	"\x06dns_ms\x18\f \x01(\x01R\x05dnsMs\x12\x1d\n" +
+	"\n" +
+	"connect_ms\x18\r \x01(\x01R\tconnectMs\x12\x15\n" +
+	"\x06tls_ms\x18\x0e \x01(\x01R\x05tlsMs\x12 \n" +
+	"\tserver_ms\x18\x0f \x01(\x01H\x00R\bserverMs\x88\x01\x01\x12\x16\n" +
+	"\x06reason\x18\x10 \x01(\tR\x06reason\x12\x1a\n" +
+	"\bcategory\x18\x11 \x01(\tR\bcategory\x12 \n" +
+	"\vmaintenance\x18\x12 \x01(\bR\vmaintenance\x12\x1d\n" +
+	"\n" +
+	"body_bytes\x18\x13 \x01(\x03R\tbodyBytes\x12%\n" +
+	"\x0eresponse_bytes\x18\x14 \x01(\x03R\rresponseBytes\x12\x14\n" +
+	"\x05allow\x18\x15 \x03(\tR\x05allow\x12\x1a\n" +
+	"\battempts\x18\x16 \x01(\x05R\battempts\x12,\n" +
+	"\x12attempt_latency_ms\x18\x17 \x01(\x01R\x10attemptLatencyMs\x12(\n" +
+	"\x10total_latency_ms\x18\x18 \x01(\x01R\x0etotalLatencyMs\x12$\n" +
+	"\x0eretry_after_ms\x18\x19 \x01(\x01R\fretryAfterMs\x127\n" +
+	"\x15retry_after_recovered\x18\x1a \x01(\bH\x01R\x13retryAfterRecovered\x88\x01\x01B\f\n" +
+	"\n" +
+	"_server_msB\x18\n" +
+	"\x16_retry_after_recoveredB\x17Z\x15health-check/resultpbb\x06proto3"
+
+var (
+	file_result_proto_rawDescOnce sync.Once
+	file_result_proto_rawDescData []byte
+)
+
+func file_result_proto_rawDescGZIP() []byte {
+	file_result_proto_rawDescOnce.Do(func() {
+		file_result_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_result_proto_rawDesc), len(file_result_proto_rawDesc)))
+	})
+	return file_result_proto_rawDescData
+}
+
+var file_result_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_result_proto_goTypes = []any{
+	(*CheckResult)(nil), // 0: healthcheck.CheckResult
+}
+var file_result_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_result_proto_init() }
+func file_result_proto_init() {
+	if File_result_proto != nil {
+		return
+	}
+	file_result_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_result_proto_rawDesc), len(file_result_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_result_proto_goTypes,
+		DependencyIndexes: file_result_proto_depIdxs,
+		MessageInfos:      file_result_proto_msgTypes,
+	}.Build()
+	File_result_proto = out.File
+	file_result_proto_goTypes = nil
+	file_result_proto_depIdxs = nil
+}
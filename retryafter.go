@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// the Retry-After delay exactly as advertised (seconds or HTTP date), uncapped;
+// ok is false when the header is absent or unparseable
+func advertisedRetryAfter(header string) (delay time.Duration, ok bool) {
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// retry_after_compliance: after a 503 or 429 advertising Retry-After, wait that long and
+// check again; the endpoint honors its backpressure contract when that check is UP,
+// otherwise it is DOWN (category retry_after). Without a Retry-After, or when the wait
+// would pass the cycle deadline, first stands and compliance is not recorded.
+func checkRetryAfter(ctx context.Context, endpoint Endpoint, deps map[string]map[string]string, first checkResult) checkResult {
+	if first.status != http.StatusServiceUnavailable && first.status != http.StatusTooManyRequests {
+		return first
+	}
+	wait, ok := advertisedRetryAfter(first.header.Get("Retry-After"))
+	if !ok {
+		return first
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+		log.Printf("%s: Retry-After %s is past the cycle deadline, compliance not checked", endpoint.Name, wait)
+		return first
+	}
+	select {
+	case <-time.After(wait):
+	case <-ctx.Done():
+		return first
+	}
+	result := sendWithRequestID(ctx, endpoint, deps)
+	recovered := result.up
+	result.waited, result.recovered = wait, &recovered
+	failure := ""
+	if !recovered {
+		failure = fmt.Sprintf("not recovered %s after status %d with Retry-After (%s)", wait, first.status, result.reason)
+		result.reason, result.category = failure, "retry_after"
+	}
+	result.assertions = append(result.assertions, assertion{name: "retry_after", failure: failure})
+	if *verbose {
+		log.Printf("%s: status %d with Retry-After %s, recovered after waiting: %t", endpoint.Name, first.status, wait, recovered)
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// next endpoint index a -sample cycle starts from (only touched by the main loop)
+var sampleCursor int
+
+// -sample as a count (>= 1) or fraction (0-1) of the endpoints; 0 checks all
+func parseSample(sample string) (float64, error) {
+	if sample == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseFloat(sample, 64)
+	if err != nil || n <= 0 || n > 1 && n != math.Trunc(n) {
+		return 0, fmt.Errorf("invalid -sample %q: want a count such as 50 or a fraction such as 0.25", sample)
+	}
+	return n, nil
+}
+
+// The next rotating subset of endpoints for this cycle, plus whatever they depend
+// on, in config order. Every endpoint is covered once every len/size cycles.
+func sampleEndpoints(endpoints []Endpoint, sample float64) []Endpoint {
+	size := int(sample)
+	if sample < 1 {
+		size = int(math.Ceil(sample * float64(len(endpoints))))
+	}
+	if sample == 0 || size >= len(endpoints) {
+		return endpoints
+	}
+	byName := make(map[string]int, len(endpoints))
+	for i, endpoint := range endpoints {
+		byName[endpoint.Name] = i
+	}
+	chosen := make([]bool, len(endpoints))
+	var choose func(i int)
+	choose = func(i int) {
+		if chosen[i] {
+			return
+		}
+		chosen[i] = true
+		// dependencies are checked in the same cycle, so they come along
+		for _, name := range dependencies(endpoints[i]) {
+			choose(byName[name])
+		}
+	}
+	start := sampleCursor % len(endpoints)
+	for k := 0; k < size; k++ {
+		choose((start + k) % len(endpoints))
+	}
+	sampleCursor = (start + size) % len(endpoints)
+	sampled := make([]Endpoint, 0, size)
+	for i, endpoint := range endpoints {
+		if chosen[i] {
+			sampled = append(sampled, endpoint)
+		}
+	}
+	return sampled
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"net"
+	"os"
+)
+
+// Send a systemd notification (e.g. "READY=1") when running under a Type=notify unit.
+// No-op unless systemd set NOTIFY_SOCKET.
+func sdNotify(state string) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return
+	}
+	// leading @ means a Linux abstract socket
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+	conn, err := net.Dial("unixgram", socket)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	conn.Write([]byte(state))
+}
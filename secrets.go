@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ${secret:name} placeholder in url, urls, headers, body and message
+var secretRef = regexp.MustCompile(`\$\{secret:([^}]+)\}`)
+
+// Read the -secrets-file, a YAML map of name: value
+func loadSecrets(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	secrets := make(map[string]string)
+	if err := yaml.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return secrets, nil
+}
+
+// Replace ${secret:name} placeholders with values from the -secrets-file (read now,
+// so a config refresh also picks up rotated secrets). Any unresolved reference fails.
+func resolveSecrets(endpoints []Endpoint) error {
+	var secrets map[string]string
+	loaded := false
+	var missing error
+	resolve := func(endpoint, value string) string {
+		return secretRef.ReplaceAllStringFunc(value, func(ref string) string {
+			name := secretRef.FindStringSubmatch(ref)[1]
+			if !loaded {
+				loaded = true
+				if *secretsFile == "" {
+					missing = fmt.Errorf("%s: refers to secret %q but no -secrets-file is set", endpoint, name)
+				} else if secrets, missing = loadSecrets(*secretsFile); missing != nil {
+					missing = fmt.Errorf("reading -secrets-file: %w", missing)
+				}
+			}
+			secret, ok := secrets[name]
+			if !ok && missing == nil {
+				missing = fmt.Errorf("%s: secret %q not found in %s", endpoint, name, *secretsFile)
+			}
+			return secret
+		})
+	}
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+		endpoint.URL = resolve(endpoint.Name, endpoint.URL)
+		for j := range endpoint.URLs {
+			endpoint.URLs[j] = resolve(endpoint.Name, endpoint.URLs[j])
+		}
+		for header, value := range endpoint.Headers {
+			endpoint.Headers[header] = resolve(endpoint.Name, value)
+		}
+		endpoint.Body = resolve(endpoint.Name, endpoint.Body)
+		endpoint.Message = resolve(endpoint.Name, endpoint.Message)
+		if missing != nil {
+			return missing
+		}
+	}
+	return nil
+}
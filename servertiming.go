@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metric used when server_timing_metric is unset, falling back to the sum of all durations
+const defaultServerTimingMetric = "total"
+
+// Server-side processing time from a Server-Timing header, e.g.
+// `db;dur=53, app;dur=47.2, total;dur=104`: the named metric's dur, or without a
+// name the "total" metric if present and otherwise the sum of every dur.
+// ok is false when there is no header or no matching duration.
+func serverTiming(header http.Header, metric string) (duration time.Duration, ok bool) {
+	var sum time.Duration
+	var summed bool
+	for _, value := range header.Values("Server-Timing") {
+		for _, entry := range strings.Split(value, ",") {
+			params := strings.Split(entry, ";")
+			name := strings.TrimSpace(params[0])
+			dur, found := serverTimingDur(params[1:])
+			if !found {
+				continue
+			}
+			if metric != "" {
+				if strings.EqualFold(name, metric) {
+					return dur, true
+				}
+				continue
+			}
+			if strings.EqualFold(name, defaultServerTimingMetric) {
+				return dur, true
+			}
+			sum += dur
+			summed = true
+		}
+	}
+	return sum, summed
+}
+
+// the dur parameter (milliseconds) among an entry's parameters
+func serverTimingDur(params []string) (time.Duration, bool) {
+	for _, param := range params {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		if !strings.EqualFold(strings.TrimSpace(key), "dur") {
+			continue
+		}
+		ms, err := strconv.ParseFloat(strings.Trim(strings.TrimSpace(value), `"`), 64)
+		if err != nil || ms < 0 {
+			return 0, false
+		}
+		return time.Duration(ms * float64(time.Millisecond)), true
+	}
+	return 0, false
+}
+
+// server_timing_budget: "" when within budget (no budget always passes)
+func serverTimingFailure(endpoint Endpoint, duration time.Duration, ok bool) string {
+	if endpoint.ServerTimingBudget <= 0 {
+		return ""
+	}
+	if !ok {
+		if endpoint.ServerTimingMetric != "" {
+			return fmt.Sprintf("no Server-Timing %s duration", endpoint.ServerTimingMetric)
+		}
+		return "no Server-Timing duration"
+	}
+	if duration > endpoint.ServerTimingBudget {
+		return fmt.Sprintf("server time %s exceeds budget %s", duration.Round(time.Millisecond), endpoint.ServerTimingBudget)
+	}
+	return ""
+}
+
+// server-side time and the total latency of the same responses, for averages
+type serverTotals struct {
+	server, latency time.Duration
+	count           int
+}
+
+func (s *serverTotals) add(server, latency time.Duration) {
+	s.server += server
+	s.latency += latency
+	s.count++
+}
+
+// average server time and the rest of the latency (network, TLS, queuing)
+func (s serverTotals) average() (server, network time.Duration) {
+	if s.count == 0 {
+		return 0, 0
+	}
+	n := time.Duration(s.count)
+	server = s.server / n
+	return server, max(s.latency/n-server, 0)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// global settings from the config's settings block; a flag given on the command line wins
+type Settings struct {
+	Interval         time.Duration `yaml:"interval,omitempty"`          // -interval
+	Timeout          time.Duration `yaml:"timeout,omitempty"`           // -timeout
+	LatencyThreshold time.Duration `yaml:"latency_threshold,omitempty"` // -latency-threshold
+	Concurrency      int           `yaml:"concurrency,omitempty"`       // -concurrency
+}
+
+// a config is a bare list of endpoints, or a mapping with settings and endpoints
+type configFile struct {
+	Settings  Settings   `yaml:"settings,omitempty"`
+	Endpoints []Endpoint `yaml:"endpoints"`
+}
+
+// decode either config shape - unknown fields (typos) rejected in strict mode
+func decodeConfig(data []byte) (configFile, error) {
+	var config configFile
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return config, err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(*strictFlag)
+	var err error
+	if len(root.Content) > 0 && root.Content[0].Kind == yaml.MappingNode {
+		if err := checkConfigKeys(root.Content[0]); err != nil {
+			return config, err
+		}
+		err = decoder.Decode(&config)
+	} else {
+		err = decoder.Decode(&config.Endpoints)
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return config, err
+	}
+	return config, nil
+}
+
+// The mapping form holds only settings and endpoints, even without -strict: anything
+// else is most likely a single endpoint or a misindented list, which would otherwise
+// load no endpoints without an error
+func checkConfigKeys(mapping *yaml.Node) error {
+	hasEndpoints := false
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key := mapping.Content[i]
+		switch key.Value {
+		case "endpoints":
+			hasEndpoints = true
+		case "settings":
+		default:
+			return fmt.Errorf("line %d: unknown top-level key %q (a config is a list of endpoints, or a mapping with settings and endpoints)",
+				key.Line, key.Value)
+		}
+	}
+	if !hasEndpoints {
+		return fmt.Errorf("line %d: config mapping has no endpoints key", mapping.Line)
+	}
+	return nil
+}
+
+// an endpoint's concurrency can't exceed the global -concurrency (only known once
+// settings are applied, so checked after parsing)
+func validateConcurrency(endpoints []Endpoint) error {
+	for _, endpoint := range endpoints {
+		if endpoint.Concurrency < 0 || endpoint.Concurrency > *concurrency {
+			return fmt.Errorf("%s: concurrency must be between 1 and the global concurrency %d, got %d",
+				endpoint.Name, *concurrency, endpoint.Concurrency)
+		}
+	}
+	return nil
+}
+
+// Fill flags not given on the command line from settings, then check them and
+// set the check interval, client timeout and latency threshold
+func applySettings(settings Settings) error {
+	given := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	if settings.Interval != 0 && !given["interval"] {
+		*intervalFlag = settings.Interval
+	}
+	if settings.Timeout != 0 && !given["timeout"] {
+		*timeoutFlag = settings.Timeout
+	}
+	if settings.LatencyThreshold != 0 && !given["latency-threshold"] {
+		*latencyThresholdFlag = settings.LatencyThreshold
+	}
+	if settings.Concurrency != 0 && !given["concurrency"] {
+		*concurrency = settings.Concurrency
+	}
+	switch {
+	case *intervalFlag <= 0:
+		return fmt.Errorf("interval must be positive, got %s", *intervalFlag)
+	case *timeoutFlag <= 0:
+		return fmt.Errorf("timeout must be positive, got %s", *timeoutFlag)
+	case *latencyThresholdFlag <= 0:
+		return fmt.Errorf("latency threshold must be positive, got %s", *latencyThresholdFlag)
+	case *concurrency < 1:
+		return fmt.Errorf("concurrency must be at least 1, got %d", *concurrency)
+	}
+	checkInterval = *intervalFlag
+	latencyThreshold = *latencyThresholdFlag
+	httpClient.Timeout = *timeoutFlag
+	return nil
+}
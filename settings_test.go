@@ -0,0 +1,30 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeConfig(t *testing.T) {
+	tests := []struct {
+		name, config string
+		endpoints    int
+		err          string // substring, "" for success
+	}{
+		{"list", "- name: a\n  url: http://a.example.com\n", 1, ""},
+		{"mapping", "settings:\n  interval: 5s\nendpoints:\n  - name: a\n    url: http://a.example.com\n", 1, ""},
+		{"single endpoint", "name: a\nurl: http://a.example.com\n", 0, `unknown top-level key "name"`},
+		{"no endpoints", "settings:\n  interval: 5s\n", 0, "no endpoints key"},
+	}
+	for _, test := range tests {
+		config, err := decodeConfig([]byte(test.config))
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%s: %v", test.name, err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("%s: error %v, want one containing %q", test.name, err, test.err)
+		case len(config.Endpoints) != test.endpoints:
+			t.Errorf("%s: %d endpoints, want %d", test.name, len(config.Endpoints), test.endpoints)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// SIGUSR1 toggles pausing of health checks
+var pauseSignals = []os.Signal{syscall.SIGUSR1}
+
+// SIGUSR2 resets accumulated stats
+var resetSignals = []os.Signal{syscall.SIGUSR2}
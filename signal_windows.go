@@ -0,0 +1,11 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// no user-defined signals on Windows -> pausing and resetting are unsupported
+var (
+	pauseSignals = []os.Signal{}
+	resetSignals = []os.Signal{}
+)
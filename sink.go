@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"time"
+)
+
+// one check result as shipped to -syslog / -remote-log
+type resultEvent struct {
+	Time        time.Time `json:"time"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	URL         string    `json:"url"`
+	Domain      string    `json:"domain"`
+	Region      string    `json:"region,omitempty"`
+	RequestID   string    `json:"request_id,omitempty"`
+	Up          bool      `json:"up"`
+	Degraded    bool      `json:"degraded,omitempty"` // up, but e.g. rate limited (-degraded-status)
+	Status      int       `json:"status,omitempty"`
+	LatencyMs   float64   `json:"latency_ms,omitempty"`
+	DNSMs       float64   `json:"dns_ms,omitempty"`
+	ConnectMs   float64   `json:"connect_ms,omitempty"`
+	TLSMs       float64   `json:"tls_ms,omitempty"`
+	ServerMs    *float64  `json:"server_ms,omitempty"` // from Server-Timing, when sent
+	Reason      string    `json:"reason,omitempty"`
+	Category    string    `json:"category,omitempty"` // failure category, e.g. timeout
+	// in a maintenance window: not counted toward availability or alerts
+	Maintenance bool `json:"maintenance,omitempty"`
+	BodyBytes   int  `json:"body_bytes,omitempty"` // request body size, e.g. for random_body
+	// response body bytes read, up to -max-body-bytes
+	ResponseBytes int64 `json:"response_bytes,omitempty"`
+	// methods in the Allow header, for expect_allow endpoints
+	Allow []string `json:"allow,omitempty"`
+	// only when retried
+	Attempts         int     `json:"attempts,omitempty"`
+	AttemptLatencyMs float64 `json:"attempt_latency_ms,omitempty"`
+	TotalLatencyMs   float64 `json:"total_latency_ms,omitempty"`
+	// retry_after_compliance only: the advertised wait and whether the check after it was UP
+	RetryAfterMs float64 `json:"retry_after_ms,omitempty"`
+	Recovered    *bool   `json:"retry_after_recovered,omitempty"`
+}
+
+func newResultEvent(endpoint Endpoint, result checkResult) resultEvent {
+	return resultEvent{
+		Time:             time.Now(),
+		Name:             endpoint.Name,
+		Description:      endpoint.Description,
+		URL:              endpoint.target(),
+		Domain:           statsKey(endpoint),
+		Region:           *region,
+		Up:               result.up,
+		Degraded:         result.degraded,
+		Status:           result.status,
+		LatencyMs:        milliseconds(result.latency),
+		DNSMs:            milliseconds(result.phases.dns),
+		ConnectMs:        milliseconds(result.phases.connect),
+		TLSMs:            milliseconds(result.phases.tls),
+		RequestID:        result.requestID,
+		Reason:           result.reason,
+		Category:         result.category,
+		Maintenance:      result.maintenance,
+		BodyBytes:        result.bodySize,
+		ResponseBytes:    result.responseBytes,
+		Attempts:         result.attempts,
+		AttemptLatencyMs: milliseconds(result.attemptLatency),
+		TotalLatencyMs:   milliseconds(result.totalLatency),
+		ServerMs:         serverMs(result),
+		Allow:            result.allow,
+		RetryAfterMs:     milliseconds(result.waited),
+		Recovered:        result.recovered,
+	}
+}
+
+func serverMs(result checkResult) *float64 {
+	if !result.serverTimed {
+		return nil
+	}
+	ms := milliseconds(result.serverTime)
+	return &ms
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// sinks receiving every check result as a JSON line
+var resultSinks []*asyncWriter
+
+// Ship a check result to every configured sink and the /events buffer
+func emitResult(endpoint Endpoint, result checkResult) {
+	event := newResultEvent(endpoint, result)
+	recentEvents.add(event)
+	recordProtobuf(event)
+	if len(resultSinks) == 0 {
+		return
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+	for _, sink := range resultSinks {
+		sink.send(line)
+	}
+}
+
+// lines buffered per sink while it is down; newer lines are dropped once full
+const sinkBuffer = 1000
+
+// Buffered writer delivering lines in the background, reconnecting on failure
+type asyncWriter struct {
+	name  string
+	dial  func() (io.WriteCloser, error)
+	lines chan []byte
+}
+
+func newAsyncWriter(name string, dial func() (io.WriteCloser, error)) *asyncWriter {
+	w := &asyncWriter{name: name, dial: dial, lines: make(chan []byte, sinkBuffer)}
+	go w.run()
+	return w
+}
+
+// queue line without blocking the check
+func (w *asyncWriter) send(line []byte) {
+	select {
+	case w.lines <- line:
+	default:
+		log.Printf("%s: buffer full, dropping result", w.name)
+	}
+}
+
+func (w *asyncWriter) run() {
+	var conn io.WriteCloser
+	backoff := time.Second
+	for line := range w.lines {
+		// retry the same line until it is written
+		for {
+			if conn == nil {
+				var err error
+				if conn, err = w.dial(); err != nil {
+					log.Printf("%s: %v (retrying in %s)", w.name, err, backoff)
+					time.Sleep(backoff)
+					backoff = min(backoff*2, time.Minute)
+					continue
+				}
+				backoff = time.Second
+			}
+			if _, err := conn.Write(line); err != nil {
+				log.Printf("%s: write failed, reconnecting: %v", w.name, err)
+				conn.Close()
+				conn = nil
+				continue
+			}
+			break
+		}
+	}
+}
+
+// dialer for -remote-log: tcp://host:port or udp://host:port
+func remoteDialer(target string) (func() (io.WriteCloser, error), error) {
+	network, addr, ok := strings.Cut(target, "://")
+	if !ok || (network != "tcp" && network != "udp") || addr == "" {
+		return nil, fmt.Errorf("remote log %q must be tcp://host:port or udp://host:port", target)
+	}
+	return func() (io.WriteCloser, error) {
+		return net.DialTimeout(network, addr, 5*time.Second)
+	}, nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// buckets per SLO window - availability is exact to 1/sloBuckets of the window
+const sloBuckets = 60
+
+// a named rolling window from -slo-windows, e.g. "24h"
+type windowSpec struct {
+	name     string
+	duration time.Duration
+}
+
+// windows configured by -slo-windows
+var sloWindows []windowSpec
+
+// Parse a comma-separated list of durations; a "d" suffix means days (e.g. 1h,24h,7d)
+func parseWindows(list string) ([]windowSpec, error) {
+	var windows []windowSpec
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		duration, err := parseDays(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid window %q", name)
+		}
+		if duration < sloBuckets*time.Second {
+			return nil, fmt.Errorf("window %q must be at least %s", name, sloBuckets*time.Second)
+		}
+		windows = append(windows, windowSpec{name: name, duration: duration})
+	}
+	return windows, nil
+}
+
+// time.ParseDuration plus a whole-days "d" suffix, e.g. 7d
+func parseDays(value string) (time.Duration, error) {
+	if days, found := strings.CutSuffix(value, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// time-bucketed up/total counters covering one rolling window
+type sloWindow struct {
+	buckets [sloBuckets]sloBucket
+}
+
+type sloBucket struct {
+	start     time.Time // start of the bucket's time slot, zero if unused
+	total, up int
+}
+
+func (w *sloWindow) record(spec windowSpec, now time.Time, up bool) {
+	width := spec.duration / sloBuckets
+	start := now.Truncate(width)
+	bucket := &w.buckets[(start.UnixNano()/int64(width))%sloBuckets]
+	if !bucket.start.Equal(start) {
+		// slot last used a full window ago - recycle it
+		*bucket = sloBucket{start: start}
+	}
+	bucket.total++
+	if up {
+		bucket.up++
+	}
+}
+
+// availability percentage over the window, ok=false without samples
+func (w *sloWindow) availability(spec windowSpec, now time.Time) (float64, bool) {
+	width := spec.duration / sloBuckets
+	oldest := now.Truncate(width).Add(-spec.duration + width)
+	total, up := 0, 0
+	for _, bucket := range w.buckets {
+		if !bucket.start.Before(oldest) {
+			total += bucket.total
+			up += bucket.up
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return float64(up) / float64(total) * 100, true
+}
+
+// record a result in every configured window; caller holds statsMu
+func (stat *Stats) recordWindows(up bool) {
+	if len(sloWindows) == 0 {
+		return
+	}
+	if stat.windows == nil {
+		stat.windows = make([]sloWindow, len(sloWindows))
+	}
+	now := time.Now()
+	for i, spec := range sloWindows {
+		stat.windows[i].record(spec, now, up)
+	}
+}
+
+// e.g. ", 1h 99%, 24h 98%" for the summary line
+func formatWindows(stat *Stats) string {
+	if stat.windows == nil {
+		return ""
+	}
+	var b strings.Builder
+	now := time.Now()
+	for i, spec := range sloWindows {
+		if availability, ok := stat.windows[i].availability(spec, now); ok {
+			fmt.Fprintf(&b, ", %s %d%%", spec.name, roundAvailability(availability))
+		}
+	}
+	return b.String()
+}
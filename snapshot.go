@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// one domain in the -snapshot-file, flat for the Grafana JSON/Infinity datasources
+type domainSnapshot struct {
+	Domain        string     `json:"domain"`
+	Region        string     `json:"region,omitempty"`
+	Up            bool       `json:"up"` // currently UP
+	Availability  float64    `json:"availability"`
+	Total         int        `json:"total"`
+	LatencyP50Ms  float64    `json:"latency_p50_ms"`
+	LatencyP95Ms  float64    `json:"latency_p95_ms"`
+	LatencyP99Ms  float64    `json:"latency_p99_ms"`
+	UptimeSeconds float64    `json:"uptime_seconds"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorTime *time.Time `json:"last_error_time,omitempty"`
+	Updated       time.Time  `json:"updated"`
+}
+
+// -snapshot-file: replace the file with the current per-domain snapshot after each cycle
+func writeSnapshot(stats map[string]*Stats) {
+	if *snapshotFile == "" {
+		return
+	}
+	if err := writeFileAtomic(*snapshotFile, snapshotJSON(stats)); err != nil {
+		log.Printf("Error writing snapshot file: %v", err)
+	}
+}
+
+// per-domain snapshot sorted by domain (failing ones only with -only-failures);
+// latency percentiles over the last -percentile-window samples
+func snapshotJSON(stats map[string]*Stats) []byte {
+	stats = snapshotStats(stats)
+	now := time.Now()
+	snapshots := make([]domainSnapshot, 0, len(stats))
+	for domain, stat := range stats {
+		if *onlyFailures && stat.healthy() {
+			continue
+		}
+		snapshot := domainSnapshot{
+			Domain:    domain,
+			Region:    *region,
+			Up:        !stat.upSince.IsZero(),
+			Total:     stat.totalRequests,
+			LastError: stat.lastError,
+			Updated:   now,
+		}
+		if !stat.lastErrorAt.IsZero() {
+			snapshot.LastErrorTime = &stat.lastErrorAt
+		}
+		if stat.totalRequests > 0 {
+			snapshot.Availability = float64(stat.upRequests) / float64(stat.totalRequests) * 100
+		}
+		if len(stat.recent) > 0 {
+			snapshot.LatencyP50Ms = milliseconds(percentile(stat.recent, 50))
+			snapshot.LatencyP95Ms = milliseconds(percentile(stat.recent, 95))
+			snapshot.LatencyP99Ms = milliseconds(percentile(stat.recent, 99))
+		}
+		if snapshot.Up {
+			snapshot.UptimeSeconds = now.Sub(stat.upSince).Seconds()
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Domain < snapshots[j].Domain })
+	data, _ := json.MarshalIndent(snapshots, "", "  ")
+	return append(data, '\n')
+}
+
+// write to a temp file in the same directory and rename it over path, so
+// readers see either the old or the new file, never a partial one
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/proxy"
+)
+
+// Dial function connecting through the SOCKS5 proxy at addr ("host:port" or
+// "socks5://[user:pass@]host:port"); proxy failures name the proxy so the
+// DOWN reason is clear
+func socks5Dialer(addr string) (func(ctx context.Context, network, address string) (net.Conn, error), error) {
+	if !strings.Contains(addr, "://") {
+		addr = "socks5://" + addr
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "socks5" && u.Scheme != "socks5h" {
+		return nil, fmt.Errorf("unsupported scheme %q (use socks5://host:port)", u.Scheme)
+	}
+	dialer, err := proxy.FromURL(u, proxy.Direct)
+	if err != nil {
+		return nil, err
+	}
+	contextDialer := dialer.(proxy.ContextDialer)
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		conn, err := contextDialer.DialContext(ctx, network, address)
+		if err != nil {
+			return nil, fmt.Errorf("via socks5 proxy %s: %w", u.Host, err)
+		}
+		return conn, nil
+	}, nil
+}
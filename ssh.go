@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSH connection to the -ssh-bastion, opened on first use and re-opened after
+// it breaks; every check dial is a direct-tcpip channel over it
+type sshTunnel struct {
+	addr   string
+	config *ssh.ClientConfig
+
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+// limit on connecting and authenticating to the bastion
+const sshDialTimeout = 10 * time.Second
+
+// tunnel shared by every transport when -ssh-bastion is set
+var bastion *sshTunnel
+
+// Tunnel to target ("user@host[:port]"), authenticating with the private key
+// in keyFile and verifying the bastion against knownHostsFile ("~/" expanded)
+func newSSHTunnel(target, keyFile, knownHostsFile string) (*sshTunnel, error) {
+	user, host, ok := strings.Cut(target, "@")
+	if !ok || user == "" || host == "" {
+		return nil, fmt.Errorf("bastion %q: want user@host[:port]", target)
+	}
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "22")
+	}
+	if keyFile == "" {
+		return nil, fmt.Errorf("-ssh-key is required")
+	}
+	key, err := os.ReadFile(expandHome(keyFile))
+	if err != nil {
+		return nil, err
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", keyFile, err)
+	}
+	hostKeys, err := knownhosts.New(expandHome(knownHostsFile))
+	if err != nil {
+		return nil, err
+	}
+	return &sshTunnel{addr: host, config: &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeys,
+		Timeout:         sshDialTimeout,
+	}}, nil
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// Current SSH client, connecting if there is none
+func (t *sshTunnel) connect() (*ssh.Client, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.client != nil {
+		return t.client, nil
+	}
+	client, err := ssh.Dial("tcp", t.addr, t.config)
+	if err != nil {
+		return nil, err
+	}
+	t.client = client
+	go func() {
+		// forget the connection once it drops so the next dial reconnects
+		client.Wait()
+		t.mu.Lock()
+		if t.client == client {
+			t.client = nil
+		}
+		t.mu.Unlock()
+	}()
+	return client, nil
+}
+
+// Dial address from the bastion; failures name the bastion so the DOWN
+// reason is clear
+func (t *sshTunnel) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	type dialed struct {
+		conn net.Conn
+		err  error
+	}
+	done := make(chan dialed, 1)
+	go func() {
+		client, err := t.connect()
+		if err != nil {
+			done <- dialed{nil, err}
+			return
+		}
+		conn, err := client.DialContext(ctx, network, address)
+		done <- dialed{conn, err}
+	}()
+	select {
+	case d := <-done:
+		if d.err != nil {
+			return nil, fmt.Errorf("via ssh bastion %s: %w", t.addr, d.err)
+		}
+		return d.conn, nil
+	case <-ctx.Done():
+		go func() {
+			if d := <-done; d.conn != nil {
+				d.conn.Close()
+			}
+		}()
+		return nil, fmt.Errorf("via ssh bastion %s: %w", t.addr, ctx.Err())
+	}
+}
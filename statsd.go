@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// largest datagram sent, safely under a 1500 byte MTU after IP/UDP headers
+const statsdPacketSize = 1432
+
+// -statsd-addr: UDP connection and the latency timings buffered since the last flush
+type statsdClient struct {
+	conn    net.Conn
+	mu      sync.Mutex
+	timings []string
+}
+
+var statsd *statsdClient
+
+func newStatsD(addr string) (*statsdClient, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &statsdClient{conn: conn}, nil
+}
+
+// metric path segments keep letters, digits, _ and -, e.g. api.example.com:443 -> api_example_com_443
+func statsdName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// DogStatsD tag values can't hold the line's separators
+var statsdTagEscaper = strings.NewReplacer(",", "_", "|", "_", "#", "_", " ", "_", "\n", "_")
+
+// one metric line about a domain or endpoint (the first dimension). With -statsd-tags the
+// dimensions become DogStatsD tags, e.g. health_check.endpoint.latency:12|ms|#endpoint:api,domain:...;
+// otherwise the first is joined into the name, e.g. health_check.endpoint.api.latency:12|ms
+func statsdLine(metric string, dims [][2]string, value float64, kind string) string {
+	if *statsdTags {
+		var tags []string
+		for _, dim := range dims {
+			tags = append(tags, dim[0]+":"+statsdTagEscaper.Replace(dim[1]))
+		}
+		if *region != "" {
+			tags = append(tags, "region:"+statsdTagEscaper.Replace(*region))
+		}
+		name := *statsdPrefix + "." + metric
+		if dims[0][0] == "endpoint" {
+			name = *statsdPrefix + ".endpoint." + metric
+		}
+		return fmt.Sprintf("%s:%.6g|%s|#%s", name, value, kind, strings.Join(tags, ","))
+	}
+	name := *statsdPrefix
+	if *region != "" {
+		name += ".region." + statsdName(*region)
+	}
+	name += "." + dims[0][0] + "." + statsdName(dims[0][1]) + "." + metric
+	return fmt.Sprintf("%s:%.6g|%s", name, value, kind)
+}
+
+// buffer a check's latency for the next flush
+func statsdTiming(endpoint Endpoint, result checkResult) {
+	if statsd == nil || result.latency <= 0 || result.maintenance {
+		return
+	}
+	line := statsdLine("latency", [][2]string{{"endpoint", endpoint.Name}, {"domain", statsKey(endpoint)}},
+		milliseconds(result.latency), "ms")
+	statsd.mu.Lock()
+	statsd.timings = append(statsd.timings, line)
+	statsd.mu.Unlock()
+}
+
+// -statsd-addr: send the cycle's availability gauges per domain and endpoint and the
+// buffered latency timings, packing lines into as few datagrams as fit
+func writeStatsD(stats map[string]*Stats) {
+	if statsd == nil {
+		return
+	}
+	statsd.mu.Lock()
+	lines := statsd.timings
+	statsd.timings = nil
+	statsd.mu.Unlock()
+	stats = snapshotStats(stats)
+	domains := make([]string, 0, len(stats))
+	for domain := range stats {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	for _, domain := range domains {
+		stat := stats[domain]
+		if stat.totalRequests == 0 {
+			continue
+		}
+		lines = append(lines, statsdLine("availability", [][2]string{{"domain", domain}},
+			float64(stat.upRequests)/float64(stat.totalRequests)*100, "g"))
+		if stat.latency.count > 0 {
+			lines = append(lines, statsdLine("latency_avg", [][2]string{{"domain", domain}},
+				stat.latency.sum/float64(stat.latency.count)*1000, "g"))
+		}
+	}
+	endpoints := snapshotEndpointStats()
+	names := make([]string, 0, len(endpoints))
+	for name := range endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		stat := endpoints[name]
+		if stat.totalRequests == 0 {
+			continue
+		}
+		lines = append(lines, statsdLine("availability", [][2]string{{"endpoint", name}, {"domain", stat.domain}},
+			float64(stat.upRequests)/float64(stat.totalRequests)*100, "g"))
+	}
+	if err := statsd.send(lines); err != nil {
+		log.Printf("Error sending statsd metrics: %v", err)
+	}
+}
+
+// newline-separated lines per datagram, each datagram at most statsdPacketSize
+// bytes unless a single line is longer
+func (c *statsdClient) send(lines []string) error {
+	c.conn.SetWriteDeadline(time.Now().Add(time.Second))
+	var packet bytes.Buffer
+	for _, line := range lines {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > statsdPacketSize {
+			if _, err := c.conn.Write(packet.Bytes()); err != nil {
+				return err
+			}
+			packet.Reset()
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	if packet.Len() == 0 {
+		return nil
+	}
+	_, err := c.conn.Write(packet.Bytes())
+	return err
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// inclusive range of status codes
+type statusRange struct {
+	min, max int
+}
+
+// Set of status codes written as a comma-separated spec of codes (418),
+// classes (2xx) and ranges (200-204), e.g. "2xx,3xx,418". In YAML it may also
+// be a list, e.g. [200, 204, "3xx"].
+type StatusSpec []statusRange
+
+func parseStatusSpec(spec string) (StatusSpec, error) {
+	var parsed StatusSpec
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" {
+			continue
+		}
+		r, err := parseStatusRange(field)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, r)
+	}
+	return parsed, nil
+}
+
+func parseStatusRange(field string) (statusRange, error) {
+	invalid := fmt.Errorf("invalid status %q (want e.g. 404, 2xx or 200-204)", field)
+	code := func(s string) (int, bool) {
+		n, err := strconv.Atoi(s)
+		return n, err == nil && n >= 100 && n <= 599
+	}
+	if class, ok := strings.CutSuffix(field, "xx"); ok {
+		n, err := strconv.Atoi(class)
+		if err != nil || len(class) != 1 || n < 1 || n > 5 {
+			return statusRange{}, invalid
+		}
+		return statusRange{n * 100, n*100 + 99}, nil
+	}
+	if low, high, ok := strings.Cut(field, "-"); ok {
+		min, okMin := code(strings.TrimSpace(low))
+		max, okMax := code(strings.TrimSpace(high))
+		if !okMin || !okMax || min > max {
+			return statusRange{}, invalid
+		}
+		return statusRange{min, max}, nil
+	}
+	n, ok := code(field)
+	if !ok {
+		return statusRange{}, invalid
+	}
+	return statusRange{n, n}, nil
+}
+
+func (spec StatusSpec) match(code int) bool {
+	for _, r := range spec {
+		if code >= r.min && code <= r.max {
+			return true
+		}
+	}
+	return false
+}
+
+// accept a spec string or a list of codes/specs; invalid specs fail the config load
+func (spec *StatusSpec) UnmarshalYAML(node *yaml.Node) error {
+	var fields []string
+	switch node.Kind {
+	case yaml.ScalarNode:
+		fields = []string{node.Value}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: status list entries must be codes or specs", item.Line)
+			}
+			fields = append(fields, item.Value)
+		}
+	default:
+		return fmt.Errorf("line %d: want a status spec such as \"2xx,418\" or a list", node.Line)
+	}
+	parsed, err := parseStatusSpec(strings.Join(fields, ","))
+	if err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	*spec = parsed
+	return nil
+}
+
+// codes counted DEGRADED for endpoint: its degraded_status, else -degraded-status
+func (endpoint Endpoint) degradedStatus() StatusSpec {
+	if len(endpoint.DegradedStatus) > 0 {
+		return endpoint.DegradedStatus
+	}
+	return degradedStatuses
+}
@@ -0,0 +1,15 @@
+//go:build !windows && !plan9
+
+package main
+
+import (
+	"io"
+	"log/syslog"
+)
+
+// dialer for -syslog: local syslog daemon, facility daemon
+func syslogDialer() (func() (io.WriteCloser, error), error) {
+	return func() (io.WriteCloser, error) {
+		return syslog.New(syslog.LOG_INFO|syslog.LOG_DAEMON, "health-check")
+	}, nil
+}
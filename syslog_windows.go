@@ -0,0 +1,13 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"io"
+)
+
+// no syslog on Windows - use -remote-log instead
+func syslogDialer() (func() (io.WriteCloser, error), error) {
+	return nil, errors.New("syslog is not supported on Windows, use -remote-log")
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// values available to header/body templates, e.g. {{.Deps.login.token}}
+type templateData struct {
+	Deps map[string]map[string]string
+}
+
+// endpoint names a template reads from: {{.Deps.name.key}} or {{index .Deps "some name" "key"}}
+var templateDepRef = regexp.MustCompile(`\.Deps\.([A-Za-z0-9_]+)|index\s+\.Deps\s+"([^"]+)"`)
+
+// dependencies referenced by an endpoint's header and body templates
+func templateDeps(endpoint Endpoint) []string {
+	var names []string
+	sources := []string{endpoint.Body}
+	for _, value := range endpoint.Headers {
+		sources = append(sources, value)
+	}
+	for _, source := range sources {
+		for _, match := range templateDepRef.FindAllStringSubmatch(source, -1) {
+			name := match[1] + match[2]
+			if !contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// parse a header/body value as a template; plain values (no "{{") are left alone
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Parse(text)
+}
+
+// make sure every header/body template parses
+func validateTemplates(endpoint Endpoint) error {
+	if _, err := parseTemplate("body", endpoint.Body); err != nil {
+		return fmt.Errorf("%s: body template: %w", endpoint.Name, err)
+	}
+	for header, value := range endpoint.Headers {
+		if _, err := parseTemplate(header, value); err != nil {
+			return fmt.Errorf("%s: header %s template: %w", endpoint.Name, header, err)
+		}
+	}
+	return nil
+}
+
+// Copy of endpoint with header and body templates filled from dependency values
+func renderTemplates(endpoint Endpoint, deps map[string]map[string]string) (Endpoint, error) {
+	data := templateData{Deps: deps}
+	render := func(name, text string) (string, error) {
+		if !strings.Contains(text, "{{") {
+			return text, nil
+		}
+		tmpl, err := parseTemplate(name, text)
+		if err != nil {
+			return "", err
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, data); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+	body, err := render("body", endpoint.Body)
+	if err != nil {
+		return endpoint, err
+	}
+	endpoint.Body = body
+	if len(endpoint.Headers) > 0 {
+		headers := make(map[string]string, len(endpoint.Headers))
+		for header, value := range endpoint.Headers {
+			if headers[header], err = render(header, value); err != nil {
+				return endpoint, err
+			}
+		}
+		endpoint.Headers = headers
+	}
+	return endpoint, nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// -test-config: check every endpoint once, dependencies first, and print a
+// PASS/FAIL report with timings, status, headers and each assertion.
+// Returns the number of failed endpoints (nothing is recorded in stats).
+func testConfig(w io.Writer, endpoints []Endpoint) int {
+	ctx, cancel := context.WithTimeout(context.Background(), checkInterval)
+	defer cancel()
+	endpoints = enabledEndpoints(endpoints)
+	results := make(map[string]checkResult, len(endpoints))
+	failed := 0
+	for _, i := range dependencyOrder(endpoints) {
+		endpoint := endpoints[i]
+		var result checkResult
+		deps := make(map[string]map[string]string)
+		for _, name := range dependencies(endpoint) {
+			if dep := results[name]; !dep.up {
+				result.reason = fmt.Sprintf("dependency %s is DOWN", name)
+				break
+			}
+			deps[name] = results[name].extracted
+		}
+		if result.reason == "" {
+			result = safeCheck(ctx, endpoint, deps)
+		}
+		results[endpoint.Name] = result
+		if !result.up {
+			failed++
+		}
+		printDiagnostics(w, endpoint, result)
+	}
+	fmt.Fprintf(w, "%d of %d endpoints passed\n", len(endpoints)-failed, len(endpoints))
+	return failed
+}
+
+// endpoint indexes with every dependency before its dependents
+// (validateDependencies has already rejected cycles)
+func dependencyOrder(endpoints []Endpoint) []int {
+	byName := make(map[string]int, len(endpoints))
+	for i, endpoint := range endpoints {
+		byName[endpoint.Name] = i
+	}
+	var order []int
+	visited := make([]bool, len(endpoints))
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		for _, name := range dependencies(endpoints[i]) {
+			visit(byName[name])
+		}
+		order = append(order, i)
+	}
+	for i := range endpoints {
+		visit(i)
+	}
+	return order
+}
+
+// One endpoint's block of the -test-config report
+func printDiagnostics(w io.Writer, endpoint Endpoint, result checkResult) {
+	verdict := "PASS"
+	if !result.up {
+		verdict = "FAIL"
+	} else if result.degraded {
+		verdict = "DEGRADED"
+	}
+	fmt.Fprintf(w, "%s %s (%s %s)\n", verdict, describe(endpoint), endpoint.Method, endpoint.target())
+	round := func(d time.Duration) time.Duration { return d.Round(time.Microsecond) }
+	if endpoint.Type == typeDNS && result.latency > 0 {
+		fmt.Fprintf(w, "    resolved %s in %s\n", strings.Join(result.answers, ", "), round(result.latency))
+		printAssertions(w, result)
+		return
+	}
+	if result.requestID != "" {
+		fmt.Fprintf(w, "    %s: %s\n", *requestIDHeader, result.requestID)
+	}
+	if result.status == 0 {
+		fmt.Fprintf(w, "    %s\n\n", result.reason)
+		return
+	}
+	fmt.Fprintf(w, "    status %d, latency %s (dns %s, connect %s, tls %s)\n", result.status,
+		round(result.latency), round(result.phases.dns), round(result.phases.connect), round(result.phases.tls))
+	if result.serverTimed {
+		fmt.Fprintf(w, "    server time %s (Server-Timing), network and other %s\n", round(result.serverTime),
+			round(max(result.latency-result.serverTime, 0)))
+	}
+	if result.bodySize > 0 {
+		fmt.Fprintf(w, "    sent %d body bytes\n", result.bodySize)
+	}
+	fmt.Fprintf(w, "    read %d response body bytes\n", result.responseBytes)
+	if result.recovered != nil {
+		fmt.Fprintf(w, "    checked again after waiting out Retry-After %s\n", round(result.waited))
+	}
+	for _, redirect := range result.redirects {
+		fmt.Fprintf(w, "    redirected to %s\n", redirect)
+	}
+	if !result.certExpiry.IsZero() {
+		fmt.Fprintf(w, "    certificate expires %s\n", result.certExpiry.Format(time.RFC3339))
+	}
+	names := make([]string, 0, len(result.header))
+	for name := range result.header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "    %s: %s\n", name, strings.Join(result.header[name], ", "))
+	}
+	names = names[:0]
+	for name := range result.trailer {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "    trailer %s: %s\n", name, strings.Join(result.trailer[name], ", "))
+	}
+	if endpoint.ExpectContinue {
+		if result.continued {
+			fmt.Fprintln(w, "    got 100 Continue")
+		} else {
+			fmt.Fprintln(w, "    no 100 Continue: body sent after -expect-continue-timeout or not at all")
+		}
+	}
+	for _, line := range result.goldenDiff {
+		fmt.Fprintf(w, "    golden %s\n", line)
+	}
+	printAssertions(w, result)
+}
+
+// PASS/FAIL line per assertion, ending the endpoint's block
+func printAssertions(w io.Writer, result checkResult) {
+	for _, check := range result.assertions {
+		if check.failure == "" {
+			fmt.Fprintf(w, "    PASS %s\n", check.name)
+		} else {
+			fmt.Fprintf(w, "    FAIL %s: %s\n", check.name, check.failure)
+		}
+	}
+	if len(result.assertions) == 0 && result.reason != "" {
+		fmt.Fprintf(w, "    FAIL %s\n", result.reason)
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// -latency-start values
+const (
+	latencyFromConn = "conn"
+	latencyFromSent = "sent"
+)
+
+// DNS lookup, TCP connect and TLS handshake durations of one request
+// (zero when a pooled connection was reused)
+type phaseTimings struct {
+	dns     time.Duration
+	connect time.Duration
+	tls     time.Duration
+}
+
+// collects phaseTimings from httptrace hooks, which may fire concurrently (dual-stack dialing)
+type phaseTracer struct {
+	mu                            sync.Mutex
+	dnsStart, connStart, tlsStart time.Time
+	timings                       phaseTimings
+	continued                     bool // got 100 Continue, for expect_continue
+	// first connection asked for and request fully written, for -latency-start
+	getConn, wroteRequest time.Time
+}
+
+// Attach tracing hooks to req; read the result with tracer.result after the response
+func traceRequest(req *http.Request) (*http.Request, *phaseTracer) {
+	t := &phaseTracer{}
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { t.mark(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.since(&t.timings.dns, t.dnsStart) },
+		ConnectStart: func(string, string) {
+			t.mark(&t.connStart)
+		},
+		ConnectDone:       func(string, string, error) { t.since(&t.timings.connect, t.connStart) },
+		TLSHandshakeStart: func() { t.mark(&t.tlsStart) },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.since(&t.timings.tls, t.tlsStart)
+		},
+		Got100Continue: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.continued = true
+		},
+		GetConn:      func(string) { t.mark(&t.getConn) },
+		WroteRequest: func(httptrace.WroteRequestInfo) { t.mark(&t.wroteRequest) },
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), t
+}
+
+// record the first start of a phase
+func (t *phaseTracer) mark(start *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if start.IsZero() {
+		*start = time.Now()
+	}
+}
+
+func (t *phaseTracer) since(phase *time.Duration, start time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	*phase = time.Since(start)
+}
+
+func (t *phaseTracer) result() phaseTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.timings
+}
+
+// Where latency is measured from per -latency-start: "conn" when the transport asks for
+// a connection (network and server time, connection setup included), "sent" once the
+// request is written (server time and the response's way back only). Request building
+// before either is never counted; fallback is used if the hook didn't fire.
+func (t *phaseTracer) latencyStart(fallback time.Time) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	start := t.getConn
+	if *latencyStart == latencyFromSent {
+		start = t.wroteRequest
+	}
+	if start.IsZero() {
+		return fallback
+	}
+	return start
+}
+
+func (t *phaseTracer) got100Continue() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.continued
+}
+
+// running totals of phase timings for averaging per domain
+type phaseTotals struct {
+	sum   phaseTimings
+	count int
+}
+
+func (p *phaseTotals) add(timings phaseTimings) {
+	p.sum.dns += timings.dns
+	p.sum.connect += timings.connect
+	p.sum.tls += timings.tls
+	p.count++
+}
+
+func (p phaseTotals) average() phaseTimings {
+	if p.count == 0 {
+		return phaseTimings{}
+	}
+	n := time.Duration(p.count)
+	return phaseTimings{dns: p.sum.dns / n, connect: p.sum.connect / n, tls: p.sum.tls / n}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// weights of the newest latency in the recent and baseline moving averages:
+// recent follows roughly the last 5 samples, baseline the last 50
+const (
+	recentAlpha   = 0.2
+	baselineAlpha = 0.02
+)
+
+// samples needed before the baseline is trusted for -latency-regression
+const trendMinSamples = 20
+
+// exponentially weighted moving averages of a domain's latency
+type latencyTrend struct {
+	recent   float64 // seconds
+	baseline float64 // seconds
+	samples  int
+}
+
+func (t *latencyTrend) add(latency time.Duration) {
+	seconds := latency.Seconds()
+	if t.samples == 0 {
+		t.recent, t.baseline = seconds, seconds
+	} else {
+		t.recent += recentAlpha * (seconds - t.recent)
+		t.baseline += baselineAlpha * (seconds - t.baseline)
+	}
+	t.samples++
+}
+
+// recent average above -latency-regression times the baseline
+func (t latencyTrend) regressed() bool {
+	return *latencyRegression > 0 && t.samples >= trendMinSamples && t.recent > *latencyRegression*t.baseline
+}
+
+// summary line suffix for a regressed domain, e.g. ", latency regression (avg 320ms vs 120ms baseline)"
+func formatRegression(t latencyTrend) string {
+	if !t.regressed() {
+		return ""
+	}
+	return fmt.Sprintf(", latency regression (avg %s vs %s baseline)", seconds(t.recent), seconds(t.baseline))
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second)).Round(time.Millisecond)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// One alternative of an up_when policy: every condition set must hold.
+// The endpoint's status is OK when any alternative matches, e.g.
+// (status 200 AND body contains "OK") OR status 503.
+type UpCondition struct {
+	Status       StatusSpec `yaml:"status,omitempty"`
+	BodyContains string     `yaml:"body_contains,omitempty"`
+}
+
+type UpWhen []UpCondition
+
+func (policy UpWhen) validate(endpoint Endpoint) error {
+	if len(policy) == 0 {
+		return nil
+	}
+	if len(endpoint.ExpectedStatus) > 0 {
+		return fmt.Errorf("%s: use either up_when or expected_status", endpoint.Name)
+	}
+	for i, condition := range policy {
+		if len(condition.Status) == 0 && condition.BodyContains == "" {
+			return fmt.Errorf("%s: up_when[%d] needs status or body_contains", endpoint.Name, i)
+		}
+	}
+	return nil
+}
+
+// the response body is only read when a condition looks at it
+func (policy UpWhen) needsBody() bool {
+	for _, condition := range policy {
+		if condition.BodyContains != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// nil when some alternative matches status and body, otherwise why none did
+func (policy UpWhen) match(status int, body []byte) error {
+	for _, condition := range policy {
+		if len(condition.Status) > 0 && !condition.Status.match(status) {
+			continue
+		}
+		if condition.BodyContains != "" && !bytes.Contains(body, []byte(condition.BodyContains)) {
+			continue
+		}
+		return nil
+	}
+	if policy.needsBody() {
+		return fmt.Errorf("status %d and body match no up_when alternative", status)
+	}
+	return fmt.Errorf("status %d", status)
+}
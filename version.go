@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// set at build time, e.g.
+// go build -ldflags "-X main.version=1.4.0 -X main.commit=$(git rev-parse --short HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// commit and build date, falling back to the VCS info go build embeds
+func buildInfo() (string, string) {
+	rev, date := commit, buildDate
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && rev == "":
+				rev = setting.Value
+			case setting.Key == "vcs.time" && date == "":
+				date = setting.Value
+			}
+		}
+	}
+	if rev == "" {
+		rev = "unknown"
+	}
+	if date == "" {
+		date = "unknown"
+	}
+	return rev, date
+}
+
+// -version output
+func versionString() string {
+	rev, date := buildInfo()
+	return fmt.Sprintf("health-check %s (commit %s, built %s)", version, rev, date)
+}
+
+// sent with every check unless the endpoint sets its own User-Agent
+func userAgent() string {
+	return "health-check/" + version
+}
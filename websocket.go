@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const typeWebSocket = "websocket"
+
+// RFC 6455 opcodes used by the check
+const (
+	wsText  = 0x1
+	wsClose = 0x8
+	wsPing  = 0x9
+	wsPong  = 0xA
+)
+
+// appended to Sec-WebSocket-Key before hashing into Sec-WebSocket-Accept
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// websocket endpoints are dialed as an HTTP GET upgrade
+func prepareWebSocket(endpoint *Endpoint) error {
+	for _, target := range append([]string{endpoint.URL}, endpoint.URLs...) {
+		if target == "" {
+			continue
+		}
+		u, err := url.Parse(target)
+		if err != nil {
+			return fmt.Errorf("%s: %w", endpoint.Name, err)
+		}
+		if u.Scheme != "ws" && u.Scheme != "wss" {
+			return fmt.Errorf("%s: websocket url %q must be ws:// or wss://", endpoint.Name, target)
+		}
+	}
+	if endpoint.Body != "" {
+		return fmt.Errorf("%s: websocket endpoints send message, not body", endpoint.Name)
+	}
+	if endpoint.Method != "" && endpoint.Method != http.MethodGet {
+		return fmt.Errorf("%s: websocket endpoints must use GET", endpoint.Name)
+	}
+	endpoint.Method = http.MethodGet
+	return nil
+}
+
+// Copy of client without its Timeout: a timed-out client hides the upgraded
+// connection behind a read-only body. The caller bounds the handshake with a
+// context instead, and websocketExchange closes the connection after the timeout.
+func websocketClient(client *http.Client) *http.Client {
+	ws := *client
+	ws.Timeout = 0
+	return &ws
+}
+
+// ws(s):// -> http(s):// for the upgrade request
+func websocketURL(target string) string {
+	u, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	}
+	return u.String()
+}
+
+// Add the upgrade headers to req and return the Sec-WebSocket-Accept the server must answer with
+func websocketHandshake(req *http.Request) string {
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	key := base64.StdEncoding.EncodeToString(nonce)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	sum := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Finish the handshake on a 101 response, then send message (or a ping when empty)
+// and wait for the reply (any data frame, or the pong) until deadline.
+// Returns "" on success, otherwise why the check failed and its failure category.
+func websocketExchange(resp *http.Response, accept, message string, deadline time.Duration) (string, string) {
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return fmt.Sprintf("status %d, expected websocket upgrade", resp.StatusCode), failStatus
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != accept {
+		return "websocket handshake: bad Sec-WebSocket-Accept", failConnection
+	}
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		return "websocket handshake: connection not upgraded", failConnection
+	}
+	// unblock the read below if the server never answers
+	timer := time.AfterFunc(deadline, func() { conn.Close() })
+	defer timer.Stop()
+	opcode, want := byte(wsText), byte(0)
+	if message == "" {
+		opcode, want = wsPing, wsPong
+	}
+	if err := writeFrame(conn, opcode, []byte(message)); err != nil {
+		return fmt.Sprintf("websocket send: %v", err), errorCategory(err)
+	}
+	reader := bufio.NewReader(conn)
+	for {
+		got, payload, err := readFrame(reader)
+		if err != nil {
+			if !timer.Stop() {
+				return fmt.Sprintf("websocket: no reply within %s", deadline), failTimeout
+			}
+			return fmt.Sprintf("websocket receive: %v", err), errorCategory(err)
+		}
+		switch {
+		case got == wsClose:
+			return "websocket closed by server", failConnection
+		case got == wsPing:
+			// answer keepalives from the server and keep waiting
+			if err := writeFrame(conn, wsPong, payload); err != nil {
+				return fmt.Sprintf("websocket send: %v", err), errorCategory(err)
+			}
+		case want == wsPong && got == wsPong, want == 0 && got < wsClose:
+			writeFrame(conn, wsClose, nil)
+			return "", ""
+		}
+	}
+}
+
+// Write one final frame - clients must mask their payload
+func writeFrame(w io.Writer, opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, 0x80|byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 0x80|126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, 0x80|127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	mask := make([]byte, 4)
+	rand.Read(mask)
+	header = append(header, mask...)
+	masked := make([]byte, len(payload))
+	for i, b := range payload {
+		masked[i] = b ^ mask[i%4]
+	}
+	_, err := w.Write(append(header, masked...))
+	return err
+}
+
+// Read one frame, returning its opcode and (unmasked) payload - at most maxBodyBytes
+func readFrame(r *bufio.Reader) (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode := header[0] & 0x0F
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > maxBodyBytes {
+		return 0, nil, errors.New("frame too large")
+	}
+	var mask [4]byte
+	masked := header[1]&0x80 != 0
+	if masked {
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return opcode, payload, nil
+}